@@ -0,0 +1,54 @@
+// Command dexpaprika-exporter serves DexPaprika pool/token/system metrics
+// at /metrics for Prometheus to scrape.
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"gopkg.in/yaml.v3"
+
+	"github.com/donbagger/code-jam/go-paprika-helpers/Docs/paprikaexporter"
+)
+
+func main() {
+	configPath := flag.String("config", "exporter.yaml", "path to the watch-list config")
+	listenAddr := flag.String("listen", ":9420", "address to serve /metrics on")
+	flag.Parse()
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+
+	exporter := paprikaexporter.New(cfg)
+	prometheus.MustRegister(exporter)
+
+	http.Handle("/metrics", promhttp.Handler())
+	log.Printf("dexpaprika-exporter listening on %s", *listenAddr)
+	log.Fatal(http.ListenAndServe(*listenAddr, nil))
+}
+
+func loadConfig(path string) (paprikaexporter.Config, error) {
+	var cfg paprikaexporter.Config
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+
+	if err := yaml.Unmarshal(raw, &cfg); err != nil {
+		return cfg, err
+	}
+
+	if cfg.MinScrapeInterval == 0 {
+		cfg.MinScrapeInterval = 15 * time.Second
+	}
+
+	return cfg, nil
+}
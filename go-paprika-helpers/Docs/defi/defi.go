@@ -0,0 +1,206 @@
+// Package defi layers richer DeFi domain objects (DexPair,
+// LiquidityPosition, TradeEvent) on top of the raw Pool/Transaction
+// structs paprikahelpers returns from the DexPaprika API, modeled on
+// tzpro-go's Dex/Pair/Position types, so downstream analytics work against
+// a normalized, portable shape instead of the raw REST payload.
+package defi
+
+import (
+	"math"
+	"strconv"
+
+	paprikahelpers "github.com/donbagger/code-jam/go-paprika-helpers/Docs/internal"
+)
+
+// DexPair is a normalized liquidity pool: the two pooled tokens plus the
+// supply/fee/activity metadata needed for impermanent-loss and slippage
+// analytics.
+type DexPair struct {
+	PoolID  string
+	DexName string
+	Chain   string
+
+	TokenA paprikahelpers.Token
+	TokenB paprikahelpers.Token
+	// TokenLP identifies the pool's LP share token. DexPaprika doesn't
+	// expose a distinct LP token address, so this defaults to PoolID.
+	TokenLP string
+
+	// SupplyA, SupplyB, and SupplyLP are the pool's token reserves and LP
+	// share supply. DexPaprika's Pool doesn't report per-token reserves,
+	// so these are 0 unless a caller populates them from another source
+	// (e.g. an on-chain reserve read) before calling EstimateSlippage.
+	SupplyA  float64
+	SupplyB  float64
+	SupplyLP float64
+
+	// FeesBps is the pool's swap fee in basis points (e.g. 30 for 0.3%).
+	FeesBps int
+
+	FirstBlock    int64
+	LastTradeTime string
+	NumTrades     int
+
+	// PriceUSD is TokenA's price in USD, used as the A/B price ratio by
+	// ComputeImpermanentLoss.
+	PriceUSD float64
+}
+
+// NewDexPairFromPool builds a DexPair from a raw Pool, carrying over
+// whichever of TokenA/TokenB the pool actually reports (DexPaprika
+// normally returns exactly two).
+func NewDexPairFromPool(pool paprikahelpers.Pool) DexPair {
+	pair := DexPair{
+		PoolID:        pool.ID,
+		DexName:       pool.DexName,
+		Chain:         pool.Chain,
+		TokenLP:       pool.ID,
+		FirstBlock:    pool.CreatedAtBlockNumber,
+		LastTradeTime: pool.CreatedAt,
+		NumTrades:     pool.Transactions,
+		PriceUSD:      pool.PriceUSD,
+	}
+
+	if len(pool.Tokens) > 0 {
+		pair.TokenA = pool.Tokens[0]
+	}
+	if len(pool.Tokens) > 1 {
+		pair.TokenB = pool.Tokens[1]
+	}
+	if pool.Fee != nil {
+		pair.FeesBps = int(*pool.Fee * 10000)
+	}
+	if pool.PriceTime != nil {
+		pair.LastTradeTime = *pool.PriceTime
+	}
+
+	return pair
+}
+
+// LiquidityPosition is one liquidity provider's stake in a DexPair: what
+// they deposited, what it's currently worth, and how that compares to
+// simply holding the two assets (impermanent loss).
+type LiquidityPosition struct {
+	Owner string
+	Pair  DexPair
+
+	// Share is the fraction of the pool's LP supply this position holds,
+	// in the range 0..1.
+	Share float64
+
+	DepositedA    float64
+	DepositedB    float64
+	EntryPriceUSD float64
+
+	CurrentValueUSD float64
+	ImpermanentLoss float64
+}
+
+// NewLiquidityPosition builds a LiquidityPosition for owner's stake in
+// pair, computing CurrentValueUSD from share and pair's current reserves
+// (0 for whichever side's reserve or USD price is unset) and
+// ImpermanentLoss against entryPair, the DexPair snapshot at deposit time.
+func NewLiquidityPosition(owner string, entryPair, currentPair DexPair, share, depositedA, depositedB, entryPriceUSD float64) LiquidityPosition {
+	return LiquidityPosition{
+		Owner:           owner,
+		Pair:            currentPair,
+		Share:           share,
+		DepositedA:      depositedA,
+		DepositedB:      depositedB,
+		EntryPriceUSD:   entryPriceUSD,
+		CurrentValueUSD: share * (currentPair.SupplyA*currentPair.PriceUSD + currentPair.SupplyB*tokenBPriceUSD(currentPair)),
+		ImpermanentLoss: ComputeImpermanentLoss(entryPair, currentPair),
+	}
+}
+
+// tokenBPriceUSD returns pair.TokenB's USD price from its Summary, so
+// SupplyB (a token count) converts to USD before it's added to
+// CurrentValueUSD instead of being summed in directly. Returns 0 if
+// TokenB carries no Summary, the same "unknown means 0" convention
+// DexPair.SupplyA/B already use.
+func tokenBPriceUSD(pair DexPair) float64 {
+	if pair.TokenB.Summary == nil {
+		return 0
+	}
+	return pair.TokenB.Summary.PriceUSD
+}
+
+// ComputeImpermanentLoss estimates the impermanent loss of holding a
+// constant-product LP position from entry to current, based on how far
+// PriceUSD (the A/B price ratio) has moved between the two snapshots:
+//
+//	IL = 2*sqrt(r)/(1+r) - 1,  r = current.PriceUSD / entry.PriceUSD
+//
+// The result is <= 0: 0 means no price movement (no loss), and it grows
+// more negative the further the ratio has moved in either direction.
+// Returns 0 if entry.PriceUSD is 0 (no baseline to compare against).
+func ComputeImpermanentLoss(entry, current DexPair) float64 {
+	if entry.PriceUSD == 0 {
+		return 0
+	}
+	r := current.PriceUSD / entry.PriceUSD
+	if r <= 0 {
+		return 0
+	}
+	return 2*math.Sqrt(r)/(1+r) - 1
+}
+
+// EstimateSlippage estimates the price impact of swapping amountIn units
+// of TokenA for TokenB against pair's constant-product reserves:
+//
+//	impact = amountIn / (SupplyA + amountIn)
+//
+// Returns 0 if pair.SupplyA is unset (DexPaprika doesn't report reserves;
+// see DexPair.SupplyA), since no accurate estimate is possible without them.
+func EstimateSlippage(pair DexPair, amountIn float64) float64 {
+	if pair.SupplyA <= 0 || amountIn <= 0 {
+		return 0
+	}
+	return amountIn / (pair.SupplyA + amountIn)
+}
+
+// TradeEvent is a normalized swap, derived from a raw Transaction: which
+// direction it traded (token0 for token1, or the reverse) and the
+// magnitude of each leg.
+type TradeEvent struct {
+	PoolID string
+	TxID   string
+	Sender string
+
+	// Side is "token0_to_token1" or "token1_to_token0", inferred from the
+	// sign of the transaction's Amount0 (negative means token0 left the
+	// pool, i.e. the trader sold token1 for token0).
+	Side string
+
+	AmountIn    float64
+	AmountOut   float64
+	PriceUSD    float64
+	Timestamp   string
+	BlockNumber int64
+}
+
+// NewTradeEventFromTransaction derives a TradeEvent from a raw
+// Transaction, parsing its string-encoded Amount0/Amount1 fields.
+func NewTradeEventFromTransaction(tx paprikahelpers.Transaction) TradeEvent {
+	amount0, _ := strconv.ParseFloat(tx.Amount0, 64)
+	amount1, _ := strconv.ParseFloat(tx.Amount1, 64)
+
+	side := "token0_to_token1"
+	amountIn, amountOut := amount0, amount1
+	if amount0 < 0 {
+		side = "token1_to_token0"
+		amountIn, amountOut = amount1, amount0
+	}
+
+	return TradeEvent{
+		PoolID:      tx.PoolID,
+		TxID:        tx.ID,
+		Sender:      tx.Sender,
+		Side:        side,
+		AmountIn:    math.Abs(amountIn),
+		AmountOut:   math.Abs(amountOut),
+		PriceUSD:    tx.Price0USD,
+		Timestamp:   tx.CreatedAt,
+		BlockNumber: int64(tx.CreatedAtBlockNumber),
+	}
+}
@@ -0,0 +1,129 @@
+package paprikahelpers
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// ============================================================================
+// ADDRESS VALIDATION / NORMALIZATION
+// ============================================================================
+
+var (
+	ethAddressPattern    = regexp.MustCompile(`^0x[a-fA-F0-9]{40}$`)
+	solanaAddressPattern = regexp.MustCompile(`^[1-9A-HJ-NP-Za-km-z]{32,44}$`)
+)
+
+// ValidateTokenAddress validates an address as either an Ethereum address
+// (0x + 40 hex chars, with EIP-55 checksum verification when the address
+// is mixed-case; all-lowercase and all-uppercase are accepted as
+// unchecksummed) or a Solana address (base58, decoding to exactly 32
+// bytes).
+func ValidateTokenAddress(address string) bool {
+	if ethAddressPattern.MatchString(address) {
+		hex := address[2:]
+		if hex == strings.ToLower(hex) || hex == strings.ToUpper(hex) {
+			return true
+		}
+		return address == eip55Checksum(strings.ToLower(hex))
+	}
+
+	if solanaAddressPattern.MatchString(address) {
+		decoded, err := base58Decode(address)
+		return err == nil && len(decoded) == 32
+	}
+
+	return false
+}
+
+// NormalizeTokenAddress returns the canonical form of addr used as a map
+// key throughout the async helpers: lowercased hex for "ethereum" (and
+// EVM chains generally), passed through unchanged for "solana" since
+// base58 addresses are case-sensitive. It returns an error if addr fails
+// ValidateTokenAddress.
+func NormalizeTokenAddress(addr, chain string) (string, error) {
+	if !ValidateTokenAddress(addr) {
+		return "", fmt.Errorf("invalid token address %q for chain %q", addr, chain)
+	}
+
+	if strings.EqualFold(chain, "solana") {
+		return addr, nil
+	}
+	return strings.ToLower(addr), nil
+}
+
+// eip55Checksum returns the EIP-55 checksummed form of a lowercase,
+// unprefixed 40-character hex string: each hex letter is uppercased when
+// the corresponding nibble of keccak256(lowerHex) is >= 8.
+func eip55Checksum(lowerHex string) string {
+	hash := sha3.NewLegacyKeccak256()
+	hash.Write([]byte(lowerHex))
+	hashBytes := hash.Sum(nil)
+
+	out := []byte(lowerHex)
+	for i, c := range out {
+		if c < '0' || c > '9' {
+			var nibble byte
+			if i%2 == 0 {
+				nibble = hashBytes[i/2] >> 4
+			} else {
+				nibble = hashBytes[i/2] & 0x0f
+			}
+			if nibble >= 8 {
+				out[i] = c - 32
+			}
+		}
+	}
+
+	return "0x" + string(out)
+}
+
+// base58DecodeAlphabet is the Bitcoin/Solana base58 alphabet (no 0, O, I, l).
+const base58DecodeAlphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// base58Decode decodes s as base58, returning an error if it contains a
+// character outside the alphabet. It's implemented by hand, rather than
+// importing a base58 package, since Solana address validation is the
+// only caller and the algorithm is a couple dozen lines.
+func base58Decode(s string) ([]byte, error) {
+	var index [256]int8
+	for i := range index {
+		index[i] = -1
+	}
+	for i, c := range []byte(base58DecodeAlphabet) {
+		index[c] = int8(i)
+	}
+
+	num := new(big.Int)
+	base := big.NewInt(58)
+	digit := new(big.Int)
+
+	for _, c := range []byte(s) {
+		idx := index[c]
+		if idx < 0 {
+			return nil, fmt.Errorf("invalid base58 character %q", c)
+		}
+		digit.SetInt64(int64(idx))
+		num.Mul(num, base)
+		num.Add(num, digit)
+	}
+
+	decoded := num.Bytes()
+
+	// Leading '1's encode leading zero bytes that big.Int.Bytes() drops.
+	leadingZeros := 0
+	for _, c := range s {
+		if c != '1' {
+			break
+		}
+		leadingZeros++
+	}
+
+	out := make([]byte, leadingZeros+len(decoded))
+	copy(out[leadingZeros:], decoded)
+	return out, nil
+}
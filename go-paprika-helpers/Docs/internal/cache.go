@@ -0,0 +1,647 @@
+package paprikahelpers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andybalholm/brotli"
+	"github.com/cespare/xxhash/v2"
+	"github.com/klauspost/compress/zstd"
+)
+
+// ============================================================================
+// PLUGGABLE CACHE (in-memory + disk journal)
+// ============================================================================
+
+// Cache is the interface the package-level cache config expects. It is
+// intentionally small so in-memory, disk-backed, and tiered implementations
+// can all satisfy it.
+type Cache interface {
+	// Get returns the cached value for key and whether it was found and
+	// still within its TTL.
+	Get(key string) (interface{}, bool)
+
+	// Put stores value under key with the given TTL.
+	Put(key string, value interface{}, ttl time.Duration)
+
+	// Evict removes key, if present, regardless of TTL.
+	Evict(key string)
+
+	// Invalidate evicts every entry whose endpoint path (tracked
+	// alongside the opaque hashed keys Get/Put/Evict use) matches
+	// pattern: an exact path, or a "*"-suffixed prefix such as
+	// "/networks/eth/pools*".
+	Invalidate(pattern string)
+}
+
+// SetCache installs cache as the package-level response cache without
+// touching the current default TTL, so tests and long-lived daemons (the
+// exporter, the streaming server) can opt into a disk-backed or tiered
+// Cache without forking APIRequest. Use WithCache instead when the TTL
+// should change too.
+func SetCache(cache Cache) {
+	activeCacheMux.Lock()
+	defer activeCacheMux.Unlock()
+	activeCache = cache
+}
+
+// activeCache is the Cache implementation consulted by APIRequest. Defaults
+// to an in-memory LRU-less map matching the original behavior so existing
+// callers are unaffected until WithCache is used.
+var (
+	activeCache    Cache = newMemoryCache()
+	activeCacheTTL       = CacheDuration
+	activeCacheMux sync.RWMutex
+)
+
+// WithCache installs cache as the package-level response cache for
+// GetNetworks, GetNetworkPools, SearchEntities, GetSystemStats, and every
+// other helper built on APIRequest, with ttl as the default entry lifetime.
+// Pass a DiskCache or TieredCache to survive process restarts.
+func WithCache(cache Cache, ttl time.Duration) {
+	activeCacheMux.Lock()
+	defer activeCacheMux.Unlock()
+	activeCache = cache
+	activeCacheTTL = ttl
+}
+
+// ============================================================================
+// CACHE POLICY (per-endpoint TTL, negative caching, SWR, Vary)
+// ============================================================================
+
+// CachePolicy configures how APIRequestCtx caches responses from a
+// specific endpoint: how long a fresh entry lasts, how long an error
+// response is cached (so a consistently-failing endpoint isn't hammered;
+// a successful but empty response is cached with TTL like any other),
+// whether a stale entry is served immediately while a
+// background refresh runs, and which params actually change the response
+// (the rest are stripped from the cache key to raise the hit rate).
+type CachePolicy struct {
+	TTL                  time.Duration
+	NegativeTTL          time.Duration
+	StaleWhileRevalidate time.Duration
+	Vary                 []string
+}
+
+var (
+	cachePoliciesMu sync.RWMutex
+	cachePolicies   = make(map[string]CachePolicy)
+)
+
+// SetCachePolicy installs policy for every call to endpoint (an exact
+// path, e.g. "/networks/eth/pools", or a "*"-suffixed prefix matching a
+// family of endpoints). The most specific registered pattern wins; a call
+// to an endpoint with no matching policy falls back to
+// CachePolicy{TTL: activeCacheTTL} (no negative caching, no SWR, no Vary
+// stripping), matching APIRequestCtx's original behavior.
+func SetCachePolicy(pattern string, policy CachePolicy) {
+	cachePoliciesMu.Lock()
+	defer cachePoliciesMu.Unlock()
+	cachePolicies[pattern] = policy
+}
+
+// cachePolicyFor resolves the CachePolicy that applies to endpoint: an
+// exact match wins outright, otherwise the longest matching "*"-suffixed
+// prefix wins, otherwise the package default.
+func cachePolicyFor(endpoint string) CachePolicy {
+	cachePoliciesMu.RLock()
+	defer cachePoliciesMu.RUnlock()
+
+	if policy, ok := cachePolicies[endpoint]; ok {
+		return policy
+	}
+
+	bestLen := -1
+	var best CachePolicy
+	for pattern, policy := range cachePolicies {
+		if !strings.HasSuffix(pattern, "*") {
+			continue
+		}
+		prefix := strings.TrimSuffix(pattern, "*")
+		if strings.HasPrefix(endpoint, prefix) && len(prefix) > bestLen {
+			best, bestLen = policy, len(prefix)
+		}
+	}
+	if bestLen >= 0 {
+		return best
+	}
+
+	return CachePolicy{TTL: activeCacheTTL}
+}
+
+// cacheKeyHashSeed seeds the cache-key hash. It is a fixed constant, so a
+// given endpoint and params hash to the same key in every process (which
+// DiskCache relies on across restarts); it is not a secret.
+const cacheKeyHashSeed = 0xD5C4B3A291807F6E
+
+// hashCacheKey hashes path (an endpoint plus its cache-relevant params)
+// with a keyed xxhash, replacing the package's original unkeyed MD5.
+func hashCacheKey(path string) string {
+	h := xxhash.NewWithSeed(cacheKeyHashSeed)
+	h.Write([]byte(path))
+	return strconv.FormatUint(h.Sum64(), 16)
+}
+
+// cacheKeyPaths maps an opaque hashed cache key back to the endpoint path
+// it was derived from, purely so Invalidate can match a caller-supplied
+// endpoint pattern against keys that Get/Put/Evict otherwise treat as
+// meaningless hashes.
+var (
+	cacheKeyPathsMu sync.RWMutex
+	cacheKeyPaths   = make(map[string]string)
+)
+
+func recordCacheKeyPath(hashedKey, endpoint string) {
+	cacheKeyPathsMu.Lock()
+	defer cacheKeyPathsMu.Unlock()
+	cacheKeyPaths[hashedKey] = endpoint
+}
+
+// matchingCacheKeys returns every hashed key recorded against an endpoint
+// matching pattern: an exact path, or a "*"-suffixed prefix.
+func matchingCacheKeys(pattern string) []string {
+	prefix := strings.TrimSuffix(pattern, "*")
+	wildcard := strings.HasSuffix(pattern, "*")
+
+	cacheKeyPathsMu.RLock()
+	defer cacheKeyPathsMu.RUnlock()
+
+	var keys []string
+	for hashedKey, endpoint := range cacheKeyPaths {
+		if endpoint == pattern || (wildcard && strings.HasPrefix(endpoint, prefix)) {
+			keys = append(keys, hashedKey)
+		}
+	}
+	return keys
+}
+
+// cacheKeyMeta records, per hashed cache key, metadata the Cache
+// interface itself doesn't carry: when the entry was fetched, whether it
+// represents a cached negative (error) response, and the policy's fresh
+// TTL (needed to tell "stale but within the SWR window" apart from
+// "still fresh"), mirroring how the validator map tracks ETag/
+// Last-Modified alongside the same keys.
+type cacheKeyMeta struct {
+	fetchedAt time.Time
+	freshTTL  time.Duration
+	negative  bool
+	errMsg    string
+}
+
+var (
+	cacheKeyMetaMu  sync.RWMutex
+	cacheKeyMetaMap = make(map[string]cacheKeyMeta)
+)
+
+func setCacheKeyMeta(key string, meta cacheKeyMeta) {
+	cacheKeyMetaMu.Lock()
+	defer cacheKeyMetaMu.Unlock()
+	cacheKeyMetaMap[key] = meta
+}
+
+func getCacheKeyMeta(key string) (cacheKeyMeta, bool) {
+	cacheKeyMetaMu.RLock()
+	defer cacheKeyMetaMu.RUnlock()
+	meta, ok := cacheKeyMetaMap[key]
+	return meta, ok
+}
+
+// validator bundles the conditional-request headers remembered per cache
+// key so APIRequest can send If-None-Match/If-Modified-Since and avoid
+// re-downloading a response that hasn't changed upstream.
+type validator struct {
+	etag         string
+	lastModified string
+}
+
+var (
+	validatorsMu sync.RWMutex
+	validators   = make(map[string]validator)
+)
+
+func getValidators(key string) (etag, lastModified string, ok bool) {
+	validatorsMu.RLock()
+	defer validatorsMu.RUnlock()
+	v, ok := validators[key]
+	return v.etag, v.lastModified, ok
+}
+
+func setValidators(key, etag, lastModified string) {
+	if etag == "" && lastModified == "" {
+		return
+	}
+	validatorsMu.Lock()
+	defer validatorsMu.Unlock()
+	validators[key] = validator{etag: etag, lastModified: lastModified}
+}
+
+// memoryCache is a simple mutex-guarded map, equivalent to the cache this
+// package originally kept at file scope.
+type memoryCache struct {
+	mu      sync.RWMutex
+	entries map[string]CacheEntry
+	ttl     time.Duration
+}
+
+func newMemoryCache() *memoryCache {
+	return &memoryCache{entries: make(map[string]CacheEntry)}
+}
+
+// NewMemoryCache returns an in-memory Cache. Entries older than the ttl
+// they were Put with are treated as misses; a ttl of 0 falls back to the
+// ttl given to WithCache.
+func NewMemoryCache() Cache {
+	return newMemoryCache()
+}
+
+func (c *memoryCache) Get(key string) (interface{}, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	ttl := entry.TTL
+	if ttl <= 0 {
+		ttl = activeCacheTTL
+	}
+	if time.Since(entry.Timestamp) >= ttl {
+		return nil, false
+	}
+	return entry.Data, true
+}
+
+func (c *memoryCache) Put(key string, value interface{}, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = CacheEntry{Data: value, Timestamp: time.Now(), TTL: ttl}
+}
+
+func (c *memoryCache) Evict(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+}
+
+// Invalidate evicts every entry whose endpoint path (as tracked by
+// createCacheKey) matches pattern.
+func (c *memoryCache) Invalidate(pattern string) {
+	for _, key := range matchingCacheKeys(pattern) {
+		c.Evict(key)
+	}
+}
+
+// diskEntry is the on-disk representation of a cached response, stored one
+// JSON file per key under the cache directory.
+type diskEntry struct {
+	Data      json.RawMessage `json:"data"`
+	Timestamp time.Time       `json:"timestamp"`
+	// TTL is the lifetime this entry was stored with. <= 0 falls back to
+	// the DiskCache's own TTL field.
+	TTL time.Duration `json:"ttl,omitempty"`
+}
+
+// DiskCache persists entries as individual JSON files under Dir, so the
+// cache survives process restarts. It periodically compacts (drops expired
+// entries from disk) via a background rejournal goroutine, mirroring the
+// tx-pool journal/rejournal pattern of batching writes and trimming stale
+// state.
+type DiskCache struct {
+	Dir string
+	TTL time.Duration
+
+	mu        sync.Mutex
+	rejournal time.Duration
+	stopCh    chan struct{}
+}
+
+// NewDiskCache creates a DiskCache rooted at dir (created if missing) and
+// starts a background goroutine that compacts expired entries every
+// rejournalEvery. Call Close to stop the goroutine.
+func NewDiskCache(dir string, ttl, rejournalEvery time.Duration) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cache dir: %w", err)
+	}
+
+	dc := &DiskCache{
+		Dir:       dir,
+		TTL:       ttl,
+		rejournal: rejournalEvery,
+		stopCh:    make(chan struct{}),
+	}
+
+	if rejournalEvery > 0 {
+		go dc.rejournalLoop()
+	}
+
+	return dc, nil
+}
+
+func (d *DiskCache) path(key string) string {
+	hash := md5.Sum([]byte(key))
+	return filepath.Join(d.Dir, fmt.Sprintf("%x.cache", hash))
+}
+
+func (d *DiskCache) Get(key string) (interface{}, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	raw, err := os.ReadFile(d.path(key))
+	if err != nil || len(raw) == 0 {
+		return nil, false
+	}
+
+	payload, err := decompressCache(cacheCodec(raw[0]), raw[1:])
+	if err != nil {
+		return nil, false
+	}
+
+	var entry diskEntry
+	if err := json.Unmarshal(payload, &entry); err != nil {
+		return nil, false
+	}
+
+	effectiveTTL := entry.TTL
+	if effectiveTTL <= 0 {
+		effectiveTTL = d.TTL
+	}
+	if effectiveTTL > 0 && time.Since(entry.Timestamp) >= effectiveTTL {
+		return nil, false
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(entry.Data, &value); err != nil {
+		return nil, false
+	}
+
+	return value, true
+}
+
+func (d *DiskCache) Put(key string, value interface{}, ttl time.Duration) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+
+	entry := diskEntry{Data: raw, Timestamp: time.Now(), TTL: ttl}
+	encoded, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	codec, payload := compressCacheBest(encoded)
+	out := make([]byte, 0, len(payload)+1)
+	out = append(out, byte(codec))
+	out = append(out, payload...)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	_ = os.WriteFile(d.path(key), out, 0644)
+}
+
+// Evict removes key's on-disk entry, if any.
+func (d *DiskCache) Evict(key string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	os.Remove(d.path(key))
+}
+
+// rejournalLoop periodically walks the cache directory and removes entries
+// whose TTL has expired, keeping the on-disk journal from growing without
+// bound.
+func (d *DiskCache) rejournalLoop() {
+	ticker := time.NewTicker(d.rejournal)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.stopCh:
+			return
+		case <-ticker.C:
+			d.compact()
+		}
+	}
+}
+
+func (d *DiskCache) compact() {
+	entries, err := os.ReadDir(d.Dir)
+	if err != nil {
+		return
+	}
+
+	for _, fileEntry := range entries {
+		if fileEntry.IsDir() {
+			continue
+		}
+
+		path := filepath.Join(d.Dir, fileEntry.Name())
+		raw, err := os.ReadFile(path)
+		if err != nil || len(raw) == 0 {
+			continue
+		}
+
+		payload, err := decompressCache(cacheCodec(raw[0]), raw[1:])
+		if err != nil {
+			continue
+		}
+
+		var entry diskEntry
+		if err := json.Unmarshal(payload, &entry); err != nil {
+			continue
+		}
+
+		effectiveTTL := entry.TTL
+		if effectiveTTL <= 0 {
+			effectiveTTL = d.TTL
+		}
+		if effectiveTTL > 0 && time.Since(entry.Timestamp) >= effectiveTTL {
+			os.Remove(path)
+		}
+	}
+}
+
+// Invalidate evicts every on-disk entry whose endpoint path matches
+// pattern.
+func (d *DiskCache) Invalidate(pattern string) {
+	for _, key := range matchingCacheKeys(pattern) {
+		d.Evict(key)
+	}
+}
+
+// Close stops the background rejournal goroutine.
+func (d *DiskCache) Close() {
+	select {
+	case <-d.stopCh:
+	default:
+		close(d.stopCh)
+	}
+}
+
+// ============================================================================
+// TIERED CACHE (memory in front of disk)
+// ============================================================================
+
+// TieredCache checks Memory first and falls back to Disk, promoting disk
+// hits back into Memory so a process that restarts with a warm Disk cache
+// doesn't pay the disk-read cost again on every repeated key.
+type TieredCache struct {
+	Memory Cache
+	Disk   Cache
+}
+
+// NewTieredCache builds a TieredCache with a fresh in-memory cache in front
+// of a DiskCache rooted at dir; ttl and rejournalEvery are forwarded to
+// NewDiskCache.
+func NewTieredCache(dir string, ttl, rejournalEvery time.Duration) (*TieredCache, error) {
+	disk, err := NewDiskCache(dir, ttl, rejournalEvery)
+	if err != nil {
+		return nil, err
+	}
+	return &TieredCache{Memory: newMemoryCache(), Disk: disk}, nil
+}
+
+func (t *TieredCache) Get(key string) (interface{}, bool) {
+	if value, ok := t.Memory.Get(key); ok {
+		return value, true
+	}
+
+	value, ok := t.Disk.Get(key)
+	if !ok {
+		return nil, false
+	}
+	t.Memory.Put(key, value, 0)
+	return value, true
+}
+
+func (t *TieredCache) Put(key string, value interface{}, ttl time.Duration) {
+	t.Memory.Put(key, value, ttl)
+	t.Disk.Put(key, value, ttl)
+}
+
+func (t *TieredCache) Evict(key string) {
+	t.Memory.Evict(key)
+	t.Disk.Evict(key)
+}
+
+// Invalidate evicts every entry whose endpoint path matches pattern from
+// both tiers.
+func (t *TieredCache) Invalidate(pattern string) {
+	t.Memory.Invalidate(pattern)
+	t.Disk.Invalidate(pattern)
+}
+
+// ============================================================================
+// BEST-OF-CODEC COMPRESSION FOR DISK ENTRIES
+// ============================================================================
+
+// cacheCodec identifies the compression applied to a DiskCache entry's
+// encoded bytes. It is stored as a one-byte header so Get can decode
+// lazily without guessing the format.
+type cacheCodec byte
+
+const (
+	cacheCodecRaw cacheCodec = iota
+	cacheCodecGzip
+	cacheCodecZstd
+	cacheCodecBrotli
+)
+
+// compressCacheBest encodes data with every known codec and returns the
+// smallest result, tagged with the codec that produced it. Codecs that fail
+// to encode (shouldn't happen for any of these) are simply skipped.
+func compressCacheBest(data []byte) (cacheCodec, []byte) {
+	codec, best := cacheCodecRaw, data
+
+	if gz, err := gzipCompress(data); err == nil && len(gz) < len(best) {
+		codec, best = cacheCodecGzip, gz
+	}
+	if zs, err := zstdCompress(data); err == nil && len(zs) < len(best) {
+		codec, best = cacheCodecZstd, zs
+	}
+	if br, err := brotliCompress(data); err == nil && len(br) < len(best) {
+		codec, best = cacheCodecBrotli, br
+	}
+
+	return codec, best
+}
+
+func decompressCache(codec cacheCodec, data []byte) ([]byte, error) {
+	switch codec {
+	case cacheCodecRaw:
+		return data, nil
+	case cacheCodecGzip:
+		return gzipDecompress(data)
+	case cacheCodecZstd:
+		return zstdDecompress(data)
+	case cacheCodecBrotli:
+		return brotliDecompress(data)
+	default:
+		return nil, fmt.Errorf("unknown cache codec %d", codec)
+	}
+}
+
+func gzipCompress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func gzipDecompress(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+func zstdCompress(data []byte) ([]byte, error) {
+	enc, err := zstd.NewWriter(nil)
+	if err != nil {
+		return nil, err
+	}
+	defer enc.Close()
+	return enc.EncodeAll(data, nil), nil
+}
+
+func zstdDecompress(data []byte) ([]byte, error) {
+	dec, err := zstd.NewReader(nil)
+	if err != nil {
+		return nil, err
+	}
+	defer dec.Close()
+	return dec.DecodeAll(data, nil)
+}
+
+func brotliCompress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := brotli.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func brotliDecompress(data []byte) ([]byte, error) {
+	return io.ReadAll(brotli.NewReader(bytes.NewReader(data)))
+}
@@ -0,0 +1,270 @@
+package paprikahelpers
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// CANDLE RESAMPLING FROM TRANSACTIONS
+// ============================================================================
+
+// BuildCandles groups a transaction stream into OHLCVRecord candles at a
+// fixed interval (time.Minute, 5*time.Minute, time.Hour, 24*time.Hour,
+// ...), using each transaction's token0 USD price as the traded price and
+// its token0 amount as volume. Buckets with no transactions carry
+// forward the previous candle's close at zero volume, so the result has
+// no time gaps between the first and last observed bucket. The returned
+// slice is a plain []OHLCVRecord, so SMA/EMA/RSI/MACD/BollingerBands from
+// indicators.go apply to it directly, e.g. RSI(BuildCandles(txs, 5*time.Minute), 14).
+func BuildCandles(txs []Transaction, interval time.Duration) []OHLCVRecord {
+	trades := parseTrades(txs)
+	if len(trades) == 0 {
+		return nil
+	}
+
+	byBucket := make(map[time.Time][]trade)
+	for _, tr := range trades {
+		b := tr.t.Truncate(interval)
+		byBucket[b] = append(byBucket[b], tr)
+	}
+
+	first := trades[0].t.Truncate(interval)
+	last := trades[len(trades)-1].t.Truncate(interval)
+
+	candles := make([]OHLCVRecord, 0, int(last.Sub(first)/interval)+1)
+	prevClose := trades[0].price
+
+	for b := first; !b.After(last); b = b.Add(interval) {
+		bucketTrades := byBucket[b]
+
+		open, high, low, close := prevClose, prevClose, prevClose, prevClose
+		var volume float64
+
+		if len(bucketTrades) > 0 {
+			open = bucketTrades[0].price
+			high, low = open, open
+			for _, tr := range bucketTrades {
+				if tr.price > high {
+					high = tr.price
+				}
+				if tr.price < low {
+					low = tr.price
+				}
+				volume += tr.size
+			}
+			close = bucketTrades[len(bucketTrades)-1].price
+		}
+
+		candles = append(candles, OHLCVRecord{
+			TimeOpen:  b.Format(time.RFC3339),
+			TimeClose: b.Add(interval).Format(time.RFC3339),
+			Open:      open,
+			High:      high,
+			Low:       low,
+			Close:     close,
+			Volume:    int64(volume),
+		})
+
+		prevClose = close
+	}
+
+	return candles
+}
+
+// trade is one parsed, time-ordered transaction: a USD price and a
+// volume, stripped of everything BuildCandles/VWAPByBucket/RollingVWAP
+// don't need.
+type trade struct {
+	t     time.Time
+	pair  string
+	price float64
+	size  float64
+}
+
+// parseTrades converts txs into time-sorted trades, dropping any whose
+// CreatedAt doesn't parse as RFC3339.
+func parseTrades(txs []Transaction) []trade {
+	trades := make([]trade, 0, len(txs))
+	for _, tx := range txs {
+		t, err := time.Parse(time.RFC3339, tx.CreatedAt)
+		if err != nil {
+			continue
+		}
+		trades = append(trades, trade{
+			t:     t,
+			pair:  fmt.Sprintf("%s/%s", tx.Token0Symbol, tx.Token1Symbol),
+			price: tx.Price0USD,
+			size:  transactionSize(tx),
+		})
+	}
+
+	sort.Slice(trades, func(i, j int) bool { return trades[i].t.Before(trades[j].t) })
+	return trades
+}
+
+// transactionSize is a transaction's traded volume, taken from its token0
+// amount (amounts carry a sign indicating trade direction, which we don't
+// care about for volume purposes).
+func transactionSize(tx Transaction) float64 {
+	size, err := strconv.ParseFloat(tx.Amount0, 64)
+	if err != nil {
+		return 0
+	}
+	return math.Abs(size)
+}
+
+// ============================================================================
+// VWAP
+// ============================================================================
+
+// PairVWAP is one time-bucketed volume-weighted average price
+// observation for a single token pair.
+type PairVWAP struct {
+	Pair       string
+	BucketTime time.Time
+	VWAP       float64
+}
+
+// VWAPByBucket groups transactions by token pair and time bucket, then
+// computes Σ(price_i·volume_i) / Σ(volume_i) per bucket using each
+// transaction's token0 USD price and token0 amount. Buckets with zero
+// total volume are omitted rather than returned as a divide-by-zero.
+func VWAPByBucket(txs []Transaction, interval time.Duration) []PairVWAP {
+	type accum struct{ num, den float64 }
+	sums := make(map[string]map[time.Time]*accum)
+
+	for _, tr := range parseTrades(txs) {
+		bucket := tr.t.Truncate(interval)
+
+		if sums[tr.pair] == nil {
+			sums[tr.pair] = make(map[time.Time]*accum)
+		}
+		a := sums[tr.pair][bucket]
+		if a == nil {
+			a = &accum{}
+			sums[tr.pair][bucket] = a
+		}
+		a.num += tr.price * tr.size
+		a.den += tr.size
+	}
+
+	var out []PairVWAP
+	for pair, buckets := range sums {
+		for bucket, a := range buckets {
+			if a.den == 0 {
+				continue
+			}
+			out = append(out, PairVWAP{Pair: pair, BucketTime: bucket, VWAP: a.num / a.den})
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Pair != out[j].Pair {
+			return out[i].Pair < out[j].Pair
+		}
+		return out[i].BucketTime.Before(out[j].BucketTime)
+	})
+
+	return out
+}
+
+// rollingVWAPEntry is one transaction's contribution to a RollingVWAP's
+// running sums.
+type rollingVWAPEntry struct {
+	t    time.Time
+	num  float64 // price * size
+	size float64
+}
+
+// RollingVWAP maintains a volume-weighted average price over a sliding
+// time window, updated per transaction in O(1) amortized: a ring buffer
+// of entries backs running numerator/denominator sums, so aging entries
+// out of the window is just advancing the buffer's head instead of
+// rescanning history.
+type RollingVWAP struct {
+	window time.Duration
+
+	mu       sync.Mutex
+	buf      []rollingVWAPEntry
+	head     int
+	count    int
+	num, den float64
+}
+
+// NewRollingVWAP builds a RollingVWAP over the given sliding window.
+func NewRollingVWAP(window time.Duration) *RollingVWAP {
+	return &RollingVWAP{window: window}
+}
+
+// Add folds one transaction into the rolling window, evicting entries
+// older than window (relative to tx's own timestamp) before adding.
+func (r *RollingVWAP) Add(tx Transaction) {
+	t, err := time.Parse(time.RFC3339, tx.CreatedAt)
+	if err != nil {
+		return
+	}
+	size := transactionSize(tx)
+	entry := rollingVWAPEntry{t: t, num: tx.Price0USD * size, size: size}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cutoff := t.Add(-r.window)
+	for r.count > 0 && r.front().t.Before(cutoff) {
+		old := r.popFront()
+		r.num -= old.num
+		r.den -= old.size
+	}
+
+	r.push(entry)
+	r.num += entry.num
+	r.den += entry.size
+}
+
+// VWAP returns the current volume-weighted average price over the
+// window, or 0 if no volume has been observed.
+func (r *RollingVWAP) VWAP() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.den == 0 {
+		return 0
+	}
+	return r.num / r.den
+}
+
+func (r *RollingVWAP) front() rollingVWAPEntry {
+	return r.buf[r.head]
+}
+
+func (r *RollingVWAP) popFront() rollingVWAPEntry {
+	e := r.buf[r.head]
+	r.head = (r.head + 1) % len(r.buf)
+	r.count--
+	return e
+}
+
+func (r *RollingVWAP) push(e rollingVWAPEntry) {
+	if r.count == len(r.buf) {
+		r.grow()
+	}
+	r.buf[(r.head+r.count)%len(r.buf)] = e
+	r.count++
+}
+
+func (r *RollingVWAP) grow() {
+	newCap := len(r.buf) * 2
+	if newCap == 0 {
+		newCap = 16
+	}
+	newBuf := make([]rollingVWAPEntry, newCap)
+	for i := 0; i < r.count; i++ {
+		newBuf[i] = r.buf[(r.head+i)%len(r.buf)]
+	}
+	r.buf = newBuf
+	r.head = 0
+}
@@ -0,0 +1,241 @@
+package paprikahelpers
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestConformance runs every vector file under testdata/vectors against the
+// helper function it names, so pure functions (ValidateTokenAddress,
+// FormatNumber, FormatPercentage, CalculatePriceChange, FilterByVolume,
+// TopN) have a fixed, offline-checkable contract. Set SKIP_CONFORMANCE=1 to
+// skip this suite, e.g. in environments without the testdata checked out.
+func TestConformance(t *testing.T) {
+	if os.Getenv("SKIP_CONFORMANCE") != "" {
+		t.Skip("SKIP_CONFORMANCE set")
+	}
+
+	entries, err := os.ReadDir("testdata/vectors")
+	if err != nil {
+		t.Fatalf("failed to read testdata/vectors: %v", err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		name := entry.Name()
+		t.Run(name, func(t *testing.T) {
+			raw, err := os.ReadFile(filepath.Join("testdata/vectors", name))
+			if err != nil {
+				t.Fatalf("failed to read %s: %v", name, err)
+			}
+
+			switch name {
+			case "validate_token_address.json":
+				runValidateTokenAddressVectors(t, raw)
+			case "format_number.json":
+				runFormatNumberVectors(t, raw)
+			case "format_percentage.json":
+				runFormatPercentageVectors(t, raw)
+			case "calculate_price_change.json":
+				runCalculatePriceChangeVectors(t, raw)
+			case "filter_by_volume.json":
+				runFilterByVolumeVectors(t, raw)
+			case "top_n.json":
+				runTopNVectors(t, raw)
+			default:
+				t.Skipf("no runner registered for vector file %s", name)
+			}
+		})
+	}
+}
+
+func runValidateTokenAddressVectors(t *testing.T, raw []byte) {
+	var cases []struct {
+		Name    string `json:"name"`
+		Address string `json:"address"`
+		Valid   bool   `json:"valid"`
+	}
+	if err := json.Unmarshal(raw, &cases); err != nil {
+		t.Fatalf("failed to parse vectors: %v", err)
+	}
+
+	for _, c := range cases {
+		got := ValidateTokenAddress(c.Address)
+		if got != c.Valid {
+			t.Errorf("%s: ValidateTokenAddress(%q) = %v, want %v", c.Name, c.Address, got, c.Valid)
+		}
+	}
+}
+
+// numberOrSpecial decodes a vector's "num" field, which is either a JSON
+// number or one of the strings "NaN"/"+Inf"/"-Inf" for special-value cases.
+func numberOrSpecial(raw json.RawMessage) float64 {
+	var f float64
+	if err := json.Unmarshal(raw, &f); err == nil {
+		return f
+	}
+
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		switch s {
+		case "NaN":
+			return math.NaN()
+		case "+Inf":
+			return math.Inf(1)
+		case "-Inf":
+			return math.Inf(-1)
+		}
+	}
+
+	return math.NaN()
+}
+
+func runFormatNumberVectors(t *testing.T, raw []byte) {
+	var cases []struct {
+		Name     string          `json:"name"`
+		Num      json.RawMessage `json:"num"`
+		Decimals int             `json:"decimals"`
+		Expected string          `json:"expected"`
+	}
+	if err := json.Unmarshal(raw, &cases); err != nil {
+		t.Fatalf("failed to parse vectors: %v", err)
+	}
+
+	for _, c := range cases {
+		got := FormatNumber(numberOrSpecial(c.Num), c.Decimals)
+		if got != c.Expected {
+			t.Errorf("%s: FormatNumber(...) = %q, want %q", c.Name, got, c.Expected)
+		}
+	}
+}
+
+func runFormatPercentageVectors(t *testing.T, raw []byte) {
+	var cases []struct {
+		Name     string  `json:"name"`
+		Num      float64 `json:"num"`
+		Decimals int     `json:"decimals"`
+		Expected string  `json:"expected"`
+	}
+	if err := json.Unmarshal(raw, &cases); err != nil {
+		t.Fatalf("failed to parse vectors: %v", err)
+	}
+
+	for _, c := range cases {
+		got := FormatPercentage(c.Num, c.Decimals)
+		if got != c.Expected {
+			t.Errorf("%s: FormatPercentage(%v, %d) = %q, want %q", c.Name, c.Num, c.Decimals, got, c.Expected)
+		}
+	}
+}
+
+func runCalculatePriceChangeVectors(t *testing.T, raw []byte) {
+	var cases []struct {
+		Name     string  `json:"name"`
+		Current  float64 `json:"current"`
+		Previous float64 `json:"previous"`
+		Expected float64 `json:"expected"`
+	}
+	if err := json.Unmarshal(raw, &cases); err != nil {
+		t.Fatalf("failed to parse vectors: %v", err)
+	}
+
+	for _, c := range cases {
+		got := CalculatePriceChange(c.Current, c.Previous)
+		if got != c.Expected {
+			t.Errorf("%s: CalculatePriceChange(%v, %v) = %v, want %v", c.Name, c.Current, c.Previous, got, c.Expected)
+		}
+	}
+}
+
+func runFilterByVolumeVectors(t *testing.T, raw []byte) {
+	var cases []struct {
+		Name        string   `json:"name"`
+		MinVolume   float64  `json:"min_volume"`
+		Pools       []Pool   `json:"pools"`
+		ExpectedIDs []string `json:"expected_ids"`
+	}
+	if err := json.Unmarshal(raw, &cases); err != nil {
+		t.Fatalf("failed to parse vectors: %v", err)
+	}
+
+	for _, c := range cases {
+		got := FilterByVolume(c.Pools, c.MinVolume)
+		gotIDs := make([]string, len(got))
+		for i, p := range got {
+			gotIDs[i] = p.ID
+		}
+		if !stringSlicesEqual(gotIDs, c.ExpectedIDs) {
+			t.Errorf("%s: FilterByVolume ids = %v, want %v", c.Name, gotIDs, c.ExpectedIDs)
+		}
+	}
+}
+
+func runTopNVectors(t *testing.T, raw []byte) {
+	var cases []struct {
+		Name                 string   `json:"name"`
+		Field                string   `json:"field"`
+		N                    int      `json:"n"`
+		Pools                []Pool   `json:"pools"`
+		ExpectedIDs          []string `json:"expected_ids"`
+		ExpectedIDsUnordered []string `json:"expected_ids_unordered"`
+	}
+	if err := json.Unmarshal(raw, &cases); err != nil {
+		t.Fatalf("failed to parse vectors: %v", err)
+	}
+
+	for _, c := range cases {
+		got := TopN(c.Pools, c.Field, c.N)
+		gotIDs := make([]string, len(got))
+		for i, p := range got {
+			gotIDs[i] = p.ID
+		}
+
+		if c.ExpectedIDsUnordered != nil {
+			if !stringSetsEqual(gotIDs, c.ExpectedIDsUnordered) {
+				t.Errorf("%s: TopN ids = %v, want set %v", c.Name, gotIDs, c.ExpectedIDsUnordered)
+			}
+			continue
+		}
+
+		if !stringSlicesEqual(gotIDs, c.ExpectedIDs) {
+			t.Errorf("%s: TopN ids = %v, want %v", c.Name, gotIDs, c.ExpectedIDs)
+		}
+	}
+}
+
+func stringSlicesEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func stringSetsEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	counts := make(map[string]int)
+	for _, s := range a {
+		counts[s]++
+	}
+	for _, s := range b {
+		counts[s]--
+	}
+	for _, n := range counts {
+		if n != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,448 @@
+package paprikahelpers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// CONTEXT-AWARE API (Ctx VARIANTS)
+// ============================================================================
+
+// APIRequestCtx is APIRequest with the context threaded through
+// http.NewRequestWithContext, so a caller can cancel or time out an
+// individual request instead of relying on httpClient's fixed Timeout.
+// APIRequest delegates to this with context.Background().
+//
+// Caching is governed by endpoint's CachePolicy (see SetCachePolicy): a
+// hit within policy.TTL is returned as-is; a hit within the following
+// policy.StaleWhileRevalidate window is returned immediately while a
+// background goroutine refreshes the entry; an error response with status
+// >= 400 is cached for policy.NegativeTTL and replayed without a network
+// round trip until it expires. 429 and 5xx responses are not cached:
+// rateLimitedDo returns them as request errors after its retries.
+func APIRequestCtx(ctx context.Context, endpoint string, params map[string]string) (interface{}, error) {
+	cacheKey := createCacheKey(endpoint, params)
+	policy := cachePolicyFor(endpoint)
+
+	activeCacheMux.RLock()
+	c := activeCache
+	activeCacheMux.RUnlock()
+
+	if value, ok := c.Get(cacheKey); ok {
+		if meta, ok := getCacheKeyMeta(cacheKey); ok {
+			if meta.negative {
+				return nil, fmt.Errorf("API error: %s", meta.errMsg)
+			}
+			if meta.freshTTL > 0 && time.Since(meta.fetchedAt) >= meta.freshTTL {
+				go refreshCacheEntry(context.Background(), endpoint, params, cacheKey, policy)
+			}
+		}
+		return value, nil
+	}
+
+	u, err := url.Parse(BaseURL + endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint: %w", err)
+	}
+
+	q := u.Query()
+	for key, value := range params {
+		q.Add(key, value)
+	}
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	if etag, lastModified, ok := getValidators(cacheKey); ok {
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		if lastModified != "" {
+			req.Header.Set("If-Modified-Since", lastModified)
+		}
+	}
+
+	resp, err := rateLimitedDo(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("API request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		if value, ok := c.Get(cacheKey); ok {
+			c.Put(cacheKey, value, policy.TTL+policy.StaleWhileRevalidate)
+			return value, nil
+		}
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode >= 400 {
+		errMsg := string(body)
+		var apiErr APIError
+		if json.Unmarshal(body, &apiErr) == nil {
+			errMsg = apiErr.Error
+		}
+
+		if policy.NegativeTTL > 0 {
+			c.Put(cacheKey, nil, policy.NegativeTTL)
+			setCacheKeyMeta(cacheKey, cacheKeyMeta{negative: true, errMsg: errMsg, fetchedAt: time.Now()})
+		}
+
+		return nil, fmt.Errorf("API error: %s", errMsg)
+	}
+
+	var result interface{}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	c.Put(cacheKey, result, policy.TTL+policy.StaleWhileRevalidate)
+	setCacheKeyMeta(cacheKey, cacheKeyMeta{fetchedAt: time.Now(), freshTTL: policy.TTL})
+	setValidators(cacheKey, resp.Header.Get("ETag"), resp.Header.Get("Last-Modified"))
+
+	return result, nil
+}
+
+// refreshCacheEntry re-fetches endpoint/params in the background on behalf
+// of a stale-while-revalidate cache hit, bypassing APIRequestCtx's own
+// cache-hit branch (which would just return the stale value again)
+// entirely. Failures are silently dropped: the caller already got its
+// stale value, and the existing entry simply stays in place until it
+// falls out of the SWR window on a later call.
+func refreshCacheEntry(ctx context.Context, endpoint string, params map[string]string, cacheKey string, policy CachePolicy) {
+	activeCacheMux.RLock()
+	c := activeCache
+	activeCacheMux.RUnlock()
+
+	u, err := url.Parse(BaseURL + endpoint)
+	if err != nil {
+		return
+	}
+	q := u.Query()
+	for key, value := range params {
+		q.Add(key, value)
+	}
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return
+	}
+
+	resp, err := rateLimitedDo(ctx, req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil || resp.StatusCode >= 400 {
+		return
+	}
+
+	var result interface{}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return
+	}
+
+	c.Put(cacheKey, result, policy.TTL+policy.StaleWhileRevalidate)
+	setCacheKeyMeta(cacheKey, cacheKeyMeta{fetchedAt: time.Now(), freshTTL: policy.TTL})
+	setValidators(cacheKey, resp.Header.Get("ETag"), resp.Header.Get("Last-Modified"))
+}
+
+// GetNetworksCtx is GetNetworks with a caller-supplied context.
+func GetNetworksCtx(ctx context.Context) ([]Network, error) {
+	data, err := APIRequestCtx(ctx, "/networks", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var networks []Network
+	jsonBytes, _ := json.Marshal(data)
+	if err := json.Unmarshal(jsonBytes, &networks); err != nil {
+		return nil, fmt.Errorf("failed to parse networks: %w", err)
+	}
+
+	return networks, nil
+}
+
+// GetNetworkPoolsCtx is GetNetworkPools with a caller-supplied context.
+func GetNetworkPoolsCtx(ctx context.Context, network string, params map[string]string) (*PoolsResponse, error) {
+	if params == nil {
+		params = make(map[string]string)
+	}
+
+	data, err := APIRequestCtx(ctx, fmt.Sprintf("/networks/%s/pools", network), params)
+	if err != nil {
+		return nil, err
+	}
+
+	var response PoolsResponse
+	jsonBytes, _ := json.Marshal(data)
+	if err := json.Unmarshal(jsonBytes, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse pools response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// GetDexPoolsCtx is GetDexPools with a caller-supplied context.
+func GetDexPoolsCtx(ctx context.Context, network, dex string, params map[string]string) (*PoolsResponse, error) {
+	if params == nil {
+		params = make(map[string]string)
+	}
+
+	data, err := APIRequestCtx(ctx, fmt.Sprintf("/networks/%s/dexes/%s/pools", network, dex), params)
+	if err != nil {
+		return nil, err
+	}
+
+	var response PoolsResponse
+	jsonBytes, _ := json.Marshal(data)
+	if err := json.Unmarshal(jsonBytes, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse dex pools response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// GetNetworkDexesCtx is GetNetworkDexes with a caller-supplied context.
+func GetNetworkDexesCtx(ctx context.Context, network string, params map[string]string) (*DexesResponse, error) {
+	if params == nil {
+		params = make(map[string]string)
+	}
+
+	data, err := APIRequestCtx(ctx, fmt.Sprintf("/networks/%s/dexes", network), params)
+	if err != nil {
+		return nil, err
+	}
+
+	var response DexesResponse
+	jsonBytes, _ := json.Marshal(data)
+	if err := json.Unmarshal(jsonBytes, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse dexes response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// GetPoolDetailsCtx is GetPoolDetails with a caller-supplied context.
+func GetPoolDetailsCtx(ctx context.Context, network, poolAddress string, inversed bool) (*Pool, error) {
+	params := map[string]string{}
+	if inversed {
+		params["inversed"] = "true"
+	}
+
+	data, err := APIRequestCtx(ctx, fmt.Sprintf("/networks/%s/pools/%s", network, poolAddress), params)
+	if err != nil {
+		return nil, err
+	}
+
+	var pool Pool
+	jsonBytes, _ := json.Marshal(data)
+	if err := json.Unmarshal(jsonBytes, &pool); err != nil {
+		return nil, fmt.Errorf("failed to parse pool details: %w", err)
+	}
+
+	return &pool, nil
+}
+
+// GetPoolOHLCVCtx is GetPoolOHLCV with a caller-supplied context.
+func GetPoolOHLCVCtx(ctx context.Context, network, poolAddress, start string, params map[string]string) ([]OHLCVRecord, error) {
+	if params == nil {
+		params = make(map[string]string)
+	}
+	params["start"] = start
+
+	data, err := APIRequestCtx(ctx, fmt.Sprintf("/networks/%s/pools/%s/ohlcv", network, poolAddress), params)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []OHLCVRecord
+	jsonBytes, _ := json.Marshal(data)
+	if err := json.Unmarshal(jsonBytes, &records); err != nil {
+		return nil, fmt.Errorf("failed to parse OHLCV data: %w", err)
+	}
+
+	return records, nil
+}
+
+// GetPoolTransactionsCtx is GetPoolTransactions with a caller-supplied context.
+func GetPoolTransactionsCtx(ctx context.Context, network, poolAddress string, params map[string]string) (*TransactionsResponse, error) {
+	if params == nil {
+		params = make(map[string]string)
+	}
+
+	data, err := APIRequestCtx(ctx, fmt.Sprintf("/networks/%s/pools/%s/transactions", network, poolAddress), params)
+	if err != nil {
+		return nil, err
+	}
+
+	var response TransactionsResponse
+	jsonBytes, _ := json.Marshal(data)
+	if err := json.Unmarshal(jsonBytes, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse transactions response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// GetTokenDetailsCtx is GetTokenDetails with a caller-supplied context.
+func GetTokenDetailsCtx(ctx context.Context, network, tokenAddress string) (*Token, error) {
+	data, err := APIRequestCtx(ctx, fmt.Sprintf("/networks/%s/tokens/%s", network, tokenAddress), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var token Token
+	jsonBytes, _ := json.Marshal(data)
+	if err := json.Unmarshal(jsonBytes, &token); err != nil {
+		return nil, fmt.Errorf("failed to parse token details: %w", err)
+	}
+
+	return &token, nil
+}
+
+// GetTokenPoolsCtx is GetTokenPools with a caller-supplied context.
+func GetTokenPoolsCtx(ctx context.Context, network, tokenAddress string, params map[string]string) (*TokenPoolsResponse, error) {
+	if params == nil {
+		params = make(map[string]string)
+	}
+
+	data, err := APIRequestCtx(ctx, fmt.Sprintf("/networks/%s/tokens/%s/pools", network, tokenAddress), params)
+	if err != nil {
+		return nil, err
+	}
+
+	var response TokenPoolsResponse
+	jsonBytes, _ := json.Marshal(data)
+	if err := json.Unmarshal(jsonBytes, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse token pools response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// SearchEntitiesCtx is SearchEntities with a caller-supplied context.
+func SearchEntitiesCtx(ctx context.Context, query string) (*SearchResponse, error) {
+	params := map[string]string{"query": query}
+
+	data, err := APIRequestCtx(ctx, "/search", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var response SearchResponse
+	jsonBytes, _ := json.Marshal(data)
+	if err := json.Unmarshal(jsonBytes, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse search response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// GetSystemStatsCtx is GetSystemStats with a caller-supplied context.
+func GetSystemStatsCtx(ctx context.Context) (*SystemStats, error) {
+	data, err := APIRequestCtx(ctx, "/stats", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var stats SystemStats
+	jsonBytes, _ := json.Marshal(data)
+	if err := json.Unmarshal(jsonBytes, &stats); err != nil {
+		return nil, fmt.Errorf("failed to parse system stats: %w", err)
+	}
+
+	return &stats, nil
+}
+
+// ============================================================================
+// BATCH CLIENT
+// ============================================================================
+
+// PoolRef identifies a pool by network and address. It is comparable, so it
+// can be used directly as a map key in BatchClient results.
+type PoolRef struct {
+	Network string
+	Address string
+}
+
+// BatchResult is one BatchClient entry: either Pool is populated, or Err
+// holds the error encountered fetching it.
+type BatchResult struct {
+	Pool *Pool
+	Err  error
+}
+
+// BatchClient fans a batch of pool lookups out across a bounded worker
+// pool, the same sem-plus-WaitGroup shape Fetcher uses for async batches,
+// scoped to PoolRef keys instead of arbitrary string keys.
+type BatchClient struct {
+	// Concurrency caps the number of in-flight requests. Defaults to 8.
+	Concurrency int
+}
+
+// NewBatchClient builds a BatchClient with the given concurrency limit.
+func NewBatchClient(concurrency int) *BatchClient {
+	if concurrency <= 0 {
+		concurrency = 8
+	}
+	return &BatchClient{Concurrency: concurrency}
+}
+
+// FetchPools resolves refs concurrently via GetPoolDetailsCtx, bounding
+// in-flight requests to Concurrency, and returns one BatchResult per ref.
+// A canceled ctx stops new work from starting but still reports a result
+// for every ref, so callers get partial success instead of a bare error.
+func (b *BatchClient) FetchPools(ctx context.Context, refs []PoolRef) map[PoolRef]BatchResult {
+	results := make(map[PoolRef]BatchResult, len(refs))
+	var mu sync.Mutex
+
+	concurrency := b.Concurrency
+	if concurrency <= 0 {
+		concurrency = 8
+	}
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for _, ref := range refs {
+		select {
+		case <-ctx.Done():
+			mu.Lock()
+			results[ref] = BatchResult{Err: ctx.Err()}
+			mu.Unlock()
+			continue
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(r PoolRef) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			pool, err := GetPoolDetailsCtx(ctx, r.Network, r.Address, false)
+
+			mu.Lock()
+			results[r] = BatchResult{Pool: pool, Err: err}
+			mu.Unlock()
+		}(ref)
+	}
+
+	wg.Wait()
+	return results
+}
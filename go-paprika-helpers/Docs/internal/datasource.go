@@ -0,0 +1,224 @@
+package paprikahelpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ============================================================================
+// DATA SOURCE FALLBACK (multi-provider pool aggregation)
+// ============================================================================
+
+// DataSource is anything that can answer "give me pools for a network" in a
+// way that can be normalized into the existing Pool struct. Implementations
+// wrap a specific upstream API (DexPaprika itself, CoinGecko, DefiLlama, ...).
+type DataSource interface {
+	// Name identifies the source for logging/merge bookkeeping.
+	Name() string
+
+	// NetworkPools fetches pools for a network, normalized to []Pool.
+	NetworkPools(network string, params map[string]string) ([]Pool, error)
+}
+
+// paprikaSource is the default DataSource backed by the existing DexPaprika
+// client functions.
+type paprikaSource struct{}
+
+func (paprikaSource) Name() string { return "dexpaprika" }
+
+func (paprikaSource) NetworkPools(network string, params map[string]string) ([]Pool, error) {
+	resp, err := GetNetworkPools(network, params)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Pools, nil
+}
+
+// DefaultSource is the DexPaprika-backed DataSource, exported so callers can
+// include it explicitly in a priority list alongside fallbacks.
+var DefaultSource DataSource = paprikaSource{}
+
+// CoinGeckoSource fetches pool data from CoinGecko's public on-chain DEX
+// endpoints (no API key required).
+type CoinGeckoSource struct {
+	// BaseURL defaults to the public CoinGecko API if empty.
+	BaseURL string
+}
+
+func (s CoinGeckoSource) Name() string { return "coingecko" }
+
+func (s CoinGeckoSource) baseURL() string {
+	if s.BaseURL != "" {
+		return s.BaseURL
+	}
+	return "https://api.coingecko.com/api/v3/onchain"
+}
+
+func (s CoinGeckoSource) NetworkPools(network string, params map[string]string) ([]Pool, error) {
+	u, err := url.Parse(fmt.Sprintf("%s/networks/%s/pools", s.baseURL(), network))
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint: %w", err)
+	}
+
+	q := u.Query()
+	for k, v := range params {
+		q.Add(k, v)
+	}
+	u.RawQuery = q.Encode()
+
+	resp, err := httpClient.Get(u.String())
+	if err != nil {
+		return nil, fmt.Errorf("coingecko request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if isRetryableStatus(resp.StatusCode) {
+		return nil, fmt.Errorf("coingecko unavailable: status %d", resp.StatusCode)
+	}
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("coingecko error: status %d", resp.StatusCode)
+	}
+
+	var payload struct {
+		Data []struct {
+			Attributes struct {
+				Address           string `json:"address"`
+				Name              string `json:"name"`
+				VolumeUSD24h      string `json:"volume_usd_h24"`
+				BaseTokenPriceUSD string `json:"base_token_price_usd"`
+			} `json:"attributes"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("failed to parse coingecko response: %w", err)
+	}
+
+	pools := make([]Pool, 0, len(payload.Data))
+	for _, d := range payload.Data {
+		volume, _ := strconv.ParseFloat(d.Attributes.VolumeUSD24h, 64)
+		price, _ := strconv.ParseFloat(d.Attributes.BaseTokenPriceUSD, 64)
+		pools = append(pools, Pool{
+			ID:        d.Attributes.Address,
+			DexName:   d.Attributes.Name,
+			Chain:     network,
+			VolumeUSD: volume,
+			PriceUSD:  price,
+		})
+	}
+
+	return pools, nil
+}
+
+// DefiLlamaSource fetches pool data from DefiLlama's yields/pools endpoint.
+type DefiLlamaSource struct {
+	// BaseURL defaults to the public DefiLlama API if empty.
+	BaseURL string
+}
+
+func (s DefiLlamaSource) Name() string { return "defillama" }
+
+func (s DefiLlamaSource) baseURL() string {
+	if s.BaseURL != "" {
+		return s.BaseURL
+	}
+	return "https://yields.llama.fi"
+}
+
+func (s DefiLlamaSource) NetworkPools(network string, params map[string]string) ([]Pool, error) {
+	resp, err := httpClient.Get(s.baseURL() + "/pools")
+	if err != nil {
+		return nil, fmt.Errorf("defillama request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if isRetryableStatus(resp.StatusCode) {
+		return nil, fmt.Errorf("defillama unavailable: status %d", resp.StatusCode)
+	}
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("defillama error: status %d", resp.StatusCode)
+	}
+
+	var payload struct {
+		Data []struct {
+			Pool      string  `json:"pool"`
+			Project   string  `json:"project"`
+			Chain     string  `json:"chain"`
+			TVLUsd    float64 `json:"tvlUsd"`
+			ApyPct1D  float64 `json:"apyPct1D"`
+			VolumeUSD float64 `json:"volumeUsd1d"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("failed to parse defillama response: %w", err)
+	}
+
+	pools := make([]Pool, 0)
+	for _, d := range payload.Data {
+		if !strings.EqualFold(d.Chain, network) {
+			continue
+		}
+		pools = append(pools, Pool{
+			ID:        d.Pool,
+			DexName:   d.Project,
+			Chain:     network,
+			VolumeUSD: d.VolumeUSD,
+		})
+	}
+
+	return pools, nil
+}
+
+// isRetryableStatus reports whether an HTTP status code warrants falling
+// back to the next DataSource in priority order.
+func isRetryableStatus(code int) bool {
+	return code >= 500 || code == http.StatusTooManyRequests
+}
+
+// GetNetworkPoolsFromSources queries sources in priority order, merging pool
+// records by contract address (first source to report a given ID wins) and
+// falling back to the next source on error, 5xx, or timeout. Every source
+// is queried, not only until one succeeds; pass DefaultSource first so
+// DexPaprika's record wins for any pool several sources report.
+func GetNetworkPoolsFromSources(network string, params map[string]string, sources ...DataSource) ([]Pool, error) {
+	if len(sources) == 0 {
+		sources = []DataSource{DefaultSource}
+	}
+
+	merged := make(map[string]Pool)
+	var order []string
+	var lastErr error
+
+	for _, source := range sources {
+		pools, err := source.NetworkPools(network, params)
+		if err != nil {
+			lastErr = fmt.Errorf("%s: %w", source.Name(), err)
+			continue
+		}
+
+		for _, pool := range pools {
+			key := pool.ID
+			if key == "" {
+				key = fmt.Sprintf("%s:%s", source.Name(), pool.DexName)
+			}
+			if _, exists := merged[key]; !exists {
+				merged[key] = pool
+				order = append(order, key)
+			}
+		}
+	}
+
+	if len(merged) == 0 && lastErr != nil {
+		return nil, fmt.Errorf("all data sources failed, last error: %w", lastErr)
+	}
+
+	result := make([]Pool, 0, len(order))
+	for _, key := range order {
+		result = append(result, merged[key])
+	}
+
+	return result, nil
+}
@@ -0,0 +1,292 @@
+package paprikahelpers
+
+import (
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// ============================================================================
+// PLUGGABLE EXPORTERS
+// ============================================================================
+
+// Exporter writes a sequence of rows to some underlying format. WriteHeader
+// is called once with the requested columns, then WriteRow once per row
+// (a Pool, Token, Transaction, or map[string]interface{} analytics result),
+// and finally Close to flush and release any resources.
+type Exporter interface {
+	WriteHeader(columns []string) error
+	WriteRow(row interface{}) error
+	Close() error
+}
+
+// exporterFactory builds an Exporter around w. Registered in exporterRegistry
+// keyed by file extension.
+type exporterFactory func(w io.Writer) Exporter
+
+// exporterRegistry maps a filename extension (as returned by filepath.Ext,
+// lowercased) to the Exporter it produces. ".csv.gz" and ".jsonl.gz" are
+// handled separately in exporterFor since filepath.Ext only ever returns
+// the last extension.
+var exporterRegistry = map[string]exporterFactory{
+	".csv":     func(w io.Writer) Exporter { return newCSVExporter(w) },
+	".jsonl":   func(w io.Writer) Exporter { return newJSONLinesExporter(w) },
+	".ndjson":  func(w io.Writer) Exporter { return newJSONLinesExporter(w) },
+	".parquet": func(w io.Writer) Exporter { return newParquetExporter(w) },
+}
+
+// RegisterExporter adds or overrides the Exporter factory used for a file
+// extension (including the leading dot, e.g. ".tsv"), so callers can plug
+// in their own format without modifying Save's dispatch logic.
+func RegisterExporter(extension string, factory func(w io.Writer) Exporter) {
+	exporterRegistry[strings.ToLower(extension)] = factory
+}
+
+// exporterFor resolves filename to the Exporter that should write it,
+// wrapping the underlying file in a gzip.Writer if filename ends in ".gz".
+func exporterFor(filename string, file *os.File) (Exporter, io.Closer, error) {
+	name := filename
+	var gz *gzip.Writer
+	if ext := strings.ToLower(filepath.Ext(name)); ext == ".gz" {
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+		gz = gzip.NewWriter(file)
+	}
+
+	ext := strings.ToLower(filepath.Ext(name))
+	factory, ok := exporterRegistry[ext]
+	if !ok {
+		if gz != nil {
+			gz.Close()
+		}
+		return nil, nil, fmt.Errorf("no exporter registered for extension %q", ext)
+	}
+
+	if gz != nil {
+		return factory(gz), gz, nil
+	}
+	return factory(file), nil, nil
+}
+
+// Save writes data (a []Pool, []Token, []Transaction, or a slice of
+// map[string]interface{} analytics rows) to filename, picking the Exporter
+// from exporterRegistry by extension (".csv", ".jsonl", ".parquet", or any
+// of those with a ".gz" suffix). columns controls both the column order and,
+// for every format, which fields are included; data must be a slice, or Save
+// returns an error.
+func Save(data interface{}, filename string, columns []string) error {
+	rows := reflect.ValueOf(data)
+	if rows.Kind() != reflect.Slice {
+		return fmt.Errorf("paprikahelpers: Save requires a slice, got %T", data)
+	}
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	exporter, wrapper, err := exporterFor(filename, file)
+	if err != nil {
+		return err
+	}
+
+	if err := exporter.WriteHeader(columns); err != nil {
+		return err
+	}
+	for i := 0; i < rows.Len(); i++ {
+		if err := exporter.WriteRow(rows.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	if err := exporter.Close(); err != nil {
+		return err
+	}
+	if wrapper != nil {
+		return wrapper.Close()
+	}
+	return nil
+}
+
+// rowField extracts column's value from row, which may be a struct (Pool,
+// Token, Transaction, ...), a pointer to one, or a map[string]interface{}
+// (the shape every Extract*/Analyze* helper returns). Struct fields are
+// matched by their `json` tag, falling back to a case-insensitive field
+// name match, so the same column list works against both typed structs and
+// map-based analytics output without a per-type switch statement.
+func rowField(row interface{}, column string) interface{} {
+	if m, ok := row.(map[string]interface{}); ok {
+		return m[column]
+	}
+
+	v := reflect.ValueOf(row)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := strings.Split(field.Tag.Get("json"), ",")[0]
+		if tag == column || strings.EqualFold(field.Name, column) {
+			return v.Field(i).Interface()
+		}
+	}
+	return nil
+}
+
+// formatCSVValue renders a field value for CSV/Parquet output: floats to 2
+// decimal places (SaveToCSV, by contrast, writes price_usd to 6), everything
+// else via fmt's default verb.
+func formatCSVValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(v, 'f', 2, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', 2, 64)
+	case string:
+		return v
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// ----------------------------------------------------------------------------
+// CSV
+// ----------------------------------------------------------------------------
+
+// csvExporter is the Exporter backing the ".csv" registry entry.
+type csvExporter struct {
+	w       *csv.Writer
+	columns []string
+}
+
+func newCSVExporter(w io.Writer) *csvExporter {
+	return &csvExporter{w: csv.NewWriter(w)}
+}
+
+func (e *csvExporter) WriteHeader(columns []string) error {
+	e.columns = columns
+	return e.w.Write(columns)
+}
+
+func (e *csvExporter) WriteRow(row interface{}) error {
+	record := make([]string, len(e.columns))
+	for i, col := range e.columns {
+		record[i] = formatCSVValue(rowField(row, col))
+	}
+	return e.w.Write(record)
+}
+
+func (e *csvExporter) Close() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// ----------------------------------------------------------------------------
+// JSON Lines
+// ----------------------------------------------------------------------------
+
+// jsonLinesExporter is the Exporter backing the ".jsonl"/".ndjson" registry
+// entries: one JSON object per line, restricted to the requested columns.
+type jsonLinesExporter struct {
+	w       io.Writer
+	enc     *json.Encoder
+	columns []string
+}
+
+func newJSONLinesExporter(w io.Writer) *jsonLinesExporter {
+	return &jsonLinesExporter{w: w, enc: json.NewEncoder(w)}
+}
+
+func (e *jsonLinesExporter) WriteHeader(columns []string) error {
+	e.columns = columns
+	return nil
+}
+
+func (e *jsonLinesExporter) WriteRow(row interface{}) error {
+	object := make(map[string]interface{}, len(e.columns))
+	for _, col := range e.columns {
+		object[col] = rowField(row, col)
+	}
+	return e.enc.Encode(object)
+}
+
+func (e *jsonLinesExporter) Close() error {
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+// Parquet
+// ----------------------------------------------------------------------------
+
+// parquetRowGroupSize is how many rows parquetExporter buffers before
+// flushing a row group.
+const parquetRowGroupSize = 1000
+
+// parquetExporter is the Exporter backing the ".parquet" registry entry:
+// columnar storage, snappy-compressed, one row group per
+// parquetRowGroupSize records. Every column is stored as an optional
+// string, since the column set is only known at Save-time (it comes from
+// the caller's columns slice, not a static Go type) and Pool/Token/
+// Transaction/analytics rows mix numbers, strings, and bools freely.
+type parquetExporter struct {
+	dest    io.Writer
+	w       *parquet.Writer
+	columns []string
+}
+
+func newParquetExporter(w io.Writer) *parquetExporter {
+	return &parquetExporter{dest: w}
+}
+
+// WriteHeader builds the parquet schema from columns (every column is an
+// optional string leaf, since Save's columns come from the caller rather
+// than a static Go type) and opens the underlying row-group writer.
+func (e *parquetExporter) WriteHeader(columns []string) error {
+	e.columns = columns
+
+	group := make(parquet.Group, len(columns))
+	for _, col := range columns {
+		group[col] = parquet.Optional(parquet.String())
+	}
+	schema := parquet.NewSchema("row", group)
+
+	e.w = parquet.NewWriter(e.dest,
+		schema,
+		parquet.Compression(&parquet.Snappy),
+		parquet.MaxRowsPerRowGroup(parquetRowGroupSize),
+	)
+	return nil
+}
+
+func (e *parquetExporter) WriteRow(row interface{}) error {
+	record := make(map[string]interface{}, len(e.columns))
+	for _, col := range e.columns {
+		record[col] = formatCSVValue(rowField(row, col))
+	}
+	return e.w.Write(record)
+}
+
+func (e *parquetExporter) Close() error {
+	if e.w == nil {
+		return nil
+	}
+	return e.w.Close()
+}
@@ -0,0 +1,286 @@
+package paprikahelpers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// BOUNDED-CONCURRENCY FETCHER
+// ============================================================================
+
+// RetryPolicy controls how a Fetcher retries failed requests.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// DefaultRetryPolicy makes up to 3 attempts (2 retries) with exponential
+// backoff starting at 500ms and capped at 10s.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    10 * time.Second,
+}
+
+// FetcherOptions configures a Fetcher's concurrency and rate limiting.
+type FetcherOptions struct {
+	// Concurrency caps the number of in-flight requests. Defaults to 8.
+	Concurrency int
+	// RPS caps the steady-state request rate. Zero disables rate limiting.
+	RPS float64
+	// Burst allows short bursts above RPS. Defaults to 1 if RPS is set.
+	Burst int
+	// RetryPolicy governs retry behavior for failed requests.
+	RetryPolicy RetryPolicy
+}
+
+// FetchTask is one unit of work submitted to a Fetcher.
+type FetchTask struct {
+	// Key identifies this task in the returned results map, e.g. a network
+	// name or token address.
+	Key string
+	Run func(ctx context.Context) (interface{}, error)
+}
+
+// Fetcher runs a batch of FetchTasks with a bounded worker pool, a
+// token-bucket rate limiter honoring upstream Retry-After hints, and typed
+// error aggregation (partial success alongside per-item errors). It
+// replaces the one-goroutine-per-item pattern the original Async* helpers
+// used, so batching hundreds of networks/pools doesn't get banned or OOM
+// the process.
+type Fetcher struct {
+	opts    FetcherOptions
+	limiter *tokenBucket
+}
+
+// NewFetcher builds a Fetcher from opts, filling in defaults for any zero
+// fields.
+func NewFetcher(opts FetcherOptions) *Fetcher {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 8
+	}
+	if opts.RetryPolicy.MaxAttempts <= 0 {
+		opts.RetryPolicy = DefaultRetryPolicy
+	}
+
+	var limiter *tokenBucket
+	if opts.RPS > 0 {
+		burst := opts.Burst
+		if burst <= 0 {
+			burst = 1
+		}
+		limiter = newTokenBucket(opts.RPS, burst)
+	}
+
+	return &Fetcher{opts: opts, limiter: limiter}
+}
+
+// Run executes tasks with bounded concurrency and returns one AsyncResult
+// per task, keyed by FetchTask.Key. The returned error is the aggregate of
+// every task's error (via errors.Join), so callers can inspect partial
+// success instead of losing the whole batch to one bad input.
+func (f *Fetcher) Run(ctx context.Context, tasks []FetchTask) (map[string]AsyncResult, error) {
+	results := make(map[string]AsyncResult, len(tasks))
+	var resultsMu sync.Mutex
+
+	sem := make(chan struct{}, f.opts.Concurrency)
+	var wg sync.WaitGroup
+
+	var errMu sync.Mutex
+	var errs []error
+
+	for _, task := range tasks {
+		select {
+		case <-ctx.Done():
+			resultsMu.Lock()
+			results[task.Key] = AsyncResult{Error: ctx.Err().Error()}
+			resultsMu.Unlock()
+			errMu.Lock()
+			errs = append(errs, fmt.Errorf("%s: %w", task.Key, ctx.Err()))
+			errMu.Unlock()
+			continue
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(t FetchTask) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			value, err := f.runWithRetry(ctx, t.Run)
+
+			resultsMu.Lock()
+			if err != nil {
+				results[t.Key] = AsyncResult{Error: err.Error()}
+			} else {
+				results[t.Key] = AsyncResult{Data: value}
+			}
+			resultsMu.Unlock()
+
+			if err != nil {
+				errMu.Lock()
+				errs = append(errs, fmt.Errorf("%s: %w", t.Key, err))
+				errMu.Unlock()
+			}
+		}(task)
+	}
+
+	wg.Wait()
+
+	return results, errors.Join(errs...)
+}
+
+func (f *Fetcher) runWithRetry(ctx context.Context, run func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	var lastErr error
+	delay := f.opts.RetryPolicy.BaseDelay
+
+	for attempt := 0; attempt < f.opts.RetryPolicy.MaxAttempts; attempt++ {
+		if f.limiter != nil {
+			if err := f.limiter.Wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		value, err := run(ctx)
+		if err == nil {
+			return value, nil
+		}
+		lastErr = err
+
+		wait := delay
+		if retryAfter, ok := retryAfterFromError(err); ok {
+			wait = retryAfter
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait + jitter(wait)):
+		}
+
+		delay *= 2
+		if delay > f.opts.RetryPolicy.MaxDelay {
+			delay = f.opts.RetryPolicy.MaxDelay
+		}
+	}
+
+	return nil, lastErr
+}
+
+func jitter(base time.Duration) time.Duration {
+	if base <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(base)/2 + 1))
+}
+
+// retryAfterFromError extracts a Retry-After duration from an error message
+// of the form "... retry-after=<seconds> ...", if present.
+func retryAfterFromError(err error) (time.Duration, bool) {
+	msg := err.Error()
+	idx := strings.Index(msg, "retry-after=")
+	if idx == -1 {
+		return 0, false
+	}
+
+	rest := msg[idx+len("retry-after="):]
+	end := strings.IndexAny(rest, " \t\n")
+	if end != -1 {
+		rest = rest[:end]
+	}
+
+	seconds, convErr := strconv.Atoi(rest)
+	if convErr != nil {
+		return 0, false
+	}
+
+	return time.Duration(seconds) * time.Second, true
+}
+
+// tokenBucket is a minimal token-bucket rate limiter, used by Fetcher and by
+// the package-level limiter SetRateLimit installs. (WorkerPool uses
+// golang.org/x/time/rate instead.)
+type tokenBucket struct {
+	mu         sync.Mutex
+	tokens     float64
+	capacity   float64
+	refillRate float64 // tokens per second
+	last       time.Time
+}
+
+func newTokenBucket(rps float64, burst int) *tokenBucket {
+	return &tokenBucket{
+		tokens:     float64(burst),
+		capacity:   float64(burst),
+		refillRate: rps,
+		last:       time.Now(),
+	}
+}
+
+// Wait blocks until a token is available or ctx is done.
+func (b *tokenBucket) Wait(ctx context.Context) error {
+	for {
+		b.mu.Lock()
+		now := time.Now()
+		elapsed := now.Sub(b.last).Seconds()
+		b.tokens = math.Min(b.capacity, b.tokens+elapsed*b.refillRate)
+		b.last = now
+
+		if b.tokens >= 1 {
+			b.tokens--
+			b.mu.Unlock()
+			return nil
+		}
+
+		deficit := 1 - b.tokens
+		wait := time.Duration(deficit / b.refillRate * float64(time.Second))
+		b.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// AsyncGetMultiplePoolsWithFetcher is AsyncGetMultiplePools rebuilt on top of
+// Fetcher, so large network lists are bounded by opts.Concurrency and rate
+// limited instead of spawning one goroutine per network.
+func AsyncGetMultiplePoolsWithFetcher(ctx context.Context, networks []string, limit int, opts FetcherOptions) (map[string]interface{}, error) {
+	f := NewFetcher(opts)
+
+	tasks := make([]FetchTask, len(networks))
+	for i, network := range networks {
+		net := network
+		tasks[i] = FetchTask{
+			Key: net,
+			Run: func(ctx context.Context) (interface{}, error) {
+				params := map[string]string{"limit": strconv.Itoa(limit)}
+				return AsyncAPIRequest(ctx, fmt.Sprintf("/networks/%s/pools", net), params)
+			},
+		}
+	}
+
+	asyncResults, err := f.Run(ctx, tasks)
+
+	results := make(map[string]interface{}, len(asyncResults))
+	for key, r := range asyncResults {
+		if r.Error != "" {
+			results[key] = map[string]string{"error": r.Error}
+		} else {
+			results[key] = r.Data
+		}
+	}
+
+	return results, err
+}
@@ -0,0 +1,227 @@
+package paprikahelpers
+
+import "math"
+
+// ============================================================================
+// TECHNICAL INDICATORS
+// ============================================================================
+
+// SMA returns the simple moving average of closing prices over period,
+// aligned to records with 0 for the warmup window (indices before the
+// first full period).
+func SMA(records []OHLCVRecord, period int) []float64 {
+	closes := closingPrices(records)
+	out := make([]float64, len(closes))
+	if period <= 0 {
+		return out
+	}
+
+	var sum float64
+	for i, price := range closes {
+		sum += price
+		if i >= period {
+			sum -= closes[i-period]
+		}
+		if i >= period-1 {
+			out[i] = sum / float64(period)
+		}
+	}
+
+	return out
+}
+
+// EMA returns the exponential moving average of closing prices over
+// period, aligned to records with 0 for the warmup window. The first EMA
+// value is seeded with the SMA of the first period closes.
+func EMA(records []OHLCVRecord, period int) []float64 {
+	return emaSeries(closingPrices(records), period)
+}
+
+// RSI returns the relative strength index of closing prices over period
+// using Wilder's smoothing, aligned to records with 0 for the warmup
+// window.
+func RSI(records []OHLCVRecord, period int) []float64 {
+	closes := closingPrices(records)
+	out := make([]float64, len(closes))
+	if period <= 0 || len(closes) <= period {
+		return out
+	}
+
+	var gainSum, lossSum float64
+	for i := 1; i <= period; i++ {
+		delta := closes[i] - closes[i-1]
+		if delta > 0 {
+			gainSum += delta
+		} else {
+			lossSum += -delta
+		}
+	}
+	avgGain := gainSum / float64(period)
+	avgLoss := lossSum / float64(period)
+	out[period] = rsiFromAverages(avgGain, avgLoss)
+
+	for i := period + 1; i < len(closes); i++ {
+		delta := closes[i] - closes[i-1]
+		gain, loss := 0.0, 0.0
+		if delta > 0 {
+			gain = delta
+		} else {
+			loss = -delta
+		}
+
+		avgGain = (avgGain*float64(period-1) + gain) / float64(period)
+		avgLoss = (avgLoss*float64(period-1) + loss) / float64(period)
+		out[i] = rsiFromAverages(avgGain, avgLoss)
+	}
+
+	return out
+}
+
+func rsiFromAverages(avgGain, avgLoss float64) float64 {
+	if avgLoss == 0 {
+		if avgGain == 0 {
+			return 50
+		}
+		return 100
+	}
+	return 100 - 100/(1+avgGain/avgLoss)
+}
+
+// MACDResult holds the three aligned series MACD produces.
+type MACDResult struct {
+	MACD      []float64
+	Signal    []float64
+	Histogram []float64
+}
+
+// MACD returns the moving average convergence/divergence of closing
+// prices: MACD = EMA(fast) - EMA(slow), Signal = EMA(MACD, signal), and
+// Histogram = MACD - Signal. Pass fast=12, slow=26, signal=9 for the
+// conventional defaults.
+func MACD(records []OHLCVRecord, fast, slow, signal int) MACDResult {
+	closes := closingPrices(records)
+	if len(closes) < slow {
+		return MACDResult{
+			MACD:      make([]float64, len(closes)),
+			Signal:    make([]float64, len(closes)),
+			Histogram: make([]float64, len(closes)),
+		}
+	}
+
+	fastEMA := emaSeries(closes, fast)
+	slowEMA := emaSeries(closes, slow)
+
+	macd := make([]float64, len(closes))
+	for i := range closes {
+		if i >= slow-1 {
+			macd[i] = fastEMA[i] - slowEMA[i]
+		}
+	}
+
+	sig := emaSeries(macd[slow-1:], signal)
+	signalLine := make([]float64, len(closes))
+	copy(signalLine[slow-1:], sig)
+
+	histogram := make([]float64, len(closes))
+	for i := slow - 1 + signal - 1; i < len(closes); i++ {
+		histogram[i] = macd[i] - signalLine[i]
+	}
+
+	return MACDResult{MACD: macd, Signal: signalLine, Histogram: histogram}
+}
+
+// BollingerBandsResult holds the three aligned bands BollingerBands
+// produces.
+type BollingerBandsResult struct {
+	Upper  []float64
+	Middle []float64
+	Lower  []float64
+}
+
+// BollingerBands returns Bollinger Bands over closing prices: Middle is
+// the SMA over period, Upper/Lower are Middle ± k standard deviations of
+// the same window. Pass period=20, k=2 for the conventional defaults.
+func BollingerBands(records []OHLCVRecord, period int, k float64) BollingerBandsResult {
+	closes := closingPrices(records)
+	middle := SMA(records, period)
+
+	upper := make([]float64, len(closes))
+	lower := make([]float64, len(closes))
+
+	for i := period - 1; i < len(closes); i++ {
+		window := closes[i-period+1 : i+1]
+		stddev := stddevOf(window, middle[i])
+		upper[i] = middle[i] + k*stddev
+		lower[i] = middle[i] - k*stddev
+	}
+
+	return BollingerBandsResult{Upper: upper, Middle: middle, Lower: lower}
+}
+
+// IndicatorSet bundles the common indicators computed in one pass over a
+// single OHLCVRecord slice, for callers (like ExtractOHLCVMetrics) that
+// want the usual set without calling each function separately.
+type IndicatorSet struct {
+	SMA20     []float64
+	EMA20     []float64
+	RSI14     []float64
+	MACD      MACDResult
+	Bollinger BollingerBandsResult
+}
+
+// Indicators computes IndicatorSet's fields using their conventional
+// parameters: SMA/EMA over 20 periods, RSI over 14, MACD(12,26,9), and
+// Bollinger Bands(20, 2).
+func Indicators(records []OHLCVRecord) IndicatorSet {
+	return IndicatorSet{
+		SMA20:     SMA(records, 20),
+		EMA20:     EMA(records, 20),
+		RSI14:     RSI(records, 14),
+		MACD:      MACD(records, 12, 26, 9),
+		Bollinger: BollingerBands(records, 20, 2),
+	}
+}
+
+func closingPrices(records []OHLCVRecord) []float64 {
+	closes := make([]float64, len(records))
+	for i, r := range records {
+		closes[i] = r.Close
+	}
+	return closes
+}
+
+// emaSeries computes the EMA of an arbitrary float64 series, seeding the
+// first value with the SMA of the first period entries.
+func emaSeries(values []float64, period int) []float64 {
+	out := make([]float64, len(values))
+	if period <= 0 || len(values) < period {
+		return out
+	}
+
+	var sum float64
+	for i := 0; i < period; i++ {
+		sum += values[i]
+	}
+	out[period-1] = sum / float64(period)
+
+	multiplier := 2.0 / float64(period+1)
+	for i := period; i < len(values); i++ {
+		out[i] = (values[i]-out[i-1])*multiplier + out[i-1]
+	}
+
+	return out
+}
+
+func stddevOf(values []float64, mean float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	var variance float64
+	for _, v := range values {
+		variance += math.Pow(v-mean, 2)
+	}
+	variance /= float64(len(values))
+
+	return math.Sqrt(variance)
+}
@@ -0,0 +1,150 @@
+package paprikahelpers
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ============================================================================
+// TYPED KLINE PERIODS + CURSOR-PAGED OHLCV (GetPoolKlines/StreamPoolOHLCV)
+// ============================================================================
+
+// KlinePeriod identifies an OHLCV candle width, mirroring goex's typed
+// period constants instead of passing the interval around as a raw string.
+type KlinePeriod string
+
+const (
+	Period1m  KlinePeriod = "1m"
+	Period5m  KlinePeriod = "5m"
+	Period15m KlinePeriod = "15m"
+	Period1h  KlinePeriod = "1h"
+	Period4h  KlinePeriod = "4h"
+	Period1d  KlinePeriod = "1d"
+	Period1w  KlinePeriod = "1w"
+)
+
+// klinePageLimit is the number of candles requested per page. Paprika's
+// OHLCV endpoint caps a single response to a few hundred candles, so a
+// range wider than that is stitched together from multiple pages.
+const klinePageLimit = 500
+
+// ohlcvQuery holds the options collected from a GetPoolKlines/
+// StreamPoolOHLCV call.
+type ohlcvQuery struct {
+	since time.Time
+	until time.Time
+	limit int
+}
+
+// OHLCVOption configures a GetPoolKlines or StreamPoolOHLCV call.
+type OHLCVOption func(*ohlcvQuery)
+
+// WithSince sets the earliest candle time to fetch. Required: the
+// underlying endpoint has no "most recent N candles" mode without a start
+// time.
+func WithSince(t time.Time) OHLCVOption {
+	return func(q *ohlcvQuery) { q.since = t }
+}
+
+// WithUntil sets the latest candle time to fetch, stopping pagination once
+// it's reached. Defaults to time.Now() if unset.
+func WithUntil(t time.Time) OHLCVOption {
+	return func(q *ohlcvQuery) { q.until = t }
+}
+
+// WithLimit caps the total number of candles returned across all pages.
+// 0 (the default) means no cap beyond the Since/Until window.
+func WithLimit(n int) OHLCVOption {
+	return func(q *ohlcvQuery) { q.limit = n }
+}
+
+// GetPoolKlines fetches OHLCV candles for a pool at the given period,
+// transparently paging past the endpoint's per-request candle cap and
+// concatenating the results. It is named GetPoolKlines rather than
+// GetPoolOHLCV (the name used by the existing (network, poolAddress,
+// start string, params) helper in paprika_helpers.go) since Go doesn't
+// support overloading by signature; the goex-style typed-period/options
+// API lives alongside the original without replacing it.
+func GetPoolKlines(network, poolID string, period KlinePeriod, opts ...OHLCVOption) ([]OHLCVRecord, error) {
+	ch, err := StreamPoolOHLCV(context.Background(), network, poolID, period, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []OHLCVRecord
+	for record := range ch {
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// StreamPoolOHLCV pages through a pool's OHLCV history and delivers
+// candles on the returned channel as each page arrives, so a caller
+// processing a long time range doesn't need to buffer every candle in
+// memory at once (see GetPoolKlines for the buffered equivalent). The
+// channel is closed when the Until time is reached, a page comes back
+// empty, WithLimit's cap is hit, or ctx is canceled.
+func StreamPoolOHLCV(ctx context.Context, network, poolID string, period KlinePeriod, opts ...OHLCVOption) (<-chan OHLCVRecord, error) {
+	query := ohlcvQuery{until: time.Now()}
+	for _, opt := range opts {
+		opt(&query)
+	}
+	if query.since.IsZero() {
+		return nil, fmt.Errorf("paprikahelpers: StreamPoolOHLCV requires WithSince")
+	}
+
+	out := make(chan OHLCVRecord)
+
+	go func() {
+		defer close(out)
+
+		cursor := query.since
+		delivered := 0
+
+		for cursor.Before(query.until) {
+			params := map[string]string{
+				"interval": string(period),
+				"limit":    fmt.Sprintf("%d", klinePageLimit),
+			}
+
+			page, err := GetPoolOHLCVCtx(ctx, network, poolID, cursor.Format(time.RFC3339), params)
+			if err != nil || len(page) == 0 {
+				return
+			}
+
+			advanced := false
+			for _, record := range page {
+				closeTime, err := time.Parse(time.RFC3339, record.TimeClose)
+				if err != nil || closeTime.After(query.until) {
+					return
+				}
+
+				select {
+				case out <- record:
+				case <-ctx.Done():
+					return
+				}
+
+				delivered++
+				if query.limit > 0 && delivered >= query.limit {
+					return
+				}
+
+				if closeTime.After(cursor) {
+					cursor = closeTime
+					advanced = true
+				}
+			}
+
+			if !advanced {
+				// The page didn't move the cursor forward at all
+				// (e.g. the upstream API ignores "start" past its
+				// retention window); stop rather than loop forever.
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
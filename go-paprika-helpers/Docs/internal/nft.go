@@ -0,0 +1,89 @@
+package paprikahelpers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// ============================================================================
+// NFT COLLECTIONS / ASSETS
+// ============================================================================
+
+// ApiParams is a generic key-value parameter bag for the NFT endpoints
+// (network, limit, cursor, ...), following the same query-param shape as
+// the map[string]string used throughout the rest of the package.
+type ApiParams map[string]string
+
+func (p *ApiParams) toMap() map[string]string {
+	if p == nil {
+		return nil
+	}
+	return map[string]string(*p)
+}
+
+// GetNFTCollectionsCtx is GetNFTCollections with a caller-supplied context.
+func GetNFTCollectionsCtx(ctx context.Context, chain string, params *ApiParams) (*NFTCollectionsResponse, error) {
+	data, err := APIRequestCtx(ctx, fmt.Sprintf("/networks/%s/nfts/collections", chain), params.toMap())
+	if err != nil {
+		return nil, err
+	}
+
+	var response NFTCollectionsResponse
+	jsonBytes, _ := json.Marshal(data)
+	if err := json.Unmarshal(jsonBytes, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse NFT collections response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// GetNFTCollections retrieves NFT collections available on chain.
+func GetNFTCollections(chain string, params *ApiParams) (*NFTCollectionsResponse, error) {
+	return GetNFTCollectionsCtx(context.Background(), chain, params)
+}
+
+// GetNFTCollectionAssetsCtx is GetNFTCollectionAssets with a caller-supplied
+// context.
+func GetNFTCollectionAssetsCtx(ctx context.Context, chain, contract string, params *ApiParams) (*NFTAssetsResponse, error) {
+	data, err := APIRequestCtx(ctx, fmt.Sprintf("/networks/%s/nfts/collections/%s/assets", chain, contract), params.toMap())
+	if err != nil {
+		return nil, err
+	}
+
+	var response NFTAssetsResponse
+	jsonBytes, _ := json.Marshal(data)
+	if err := json.Unmarshal(jsonBytes, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse NFT assets response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// GetNFTCollectionAssets retrieves the assets belonging to an NFT
+// collection identified by its contract address on chain.
+func GetNFTCollectionAssets(chain, contract string, params *ApiParams) (*NFTAssetsResponse, error) {
+	return GetNFTCollectionAssetsCtx(context.Background(), chain, contract, params)
+}
+
+// GetNFTsByOwnerCtx is GetNFTsByOwner with a caller-supplied context.
+func GetNFTsByOwnerCtx(ctx context.Context, chain, owner string) ([]NFTAsset, error) {
+	data, err := APIRequestCtx(ctx, fmt.Sprintf("/networks/%s/nfts/owners/%s", chain, owner), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var assets []NFTAsset
+	jsonBytes, _ := json.Marshal(data)
+	if err := json.Unmarshal(jsonBytes, &assets); err != nil {
+		return nil, fmt.Errorf("failed to parse NFTs-by-owner response: %w", err)
+	}
+
+	return assets, nil
+}
+
+// GetNFTsByOwner retrieves every NFT held by owner on chain, across
+// collections.
+func GetNFTsByOwner(chain, owner string) ([]NFTAsset, error) {
+	return GetNFTsByOwnerCtx(context.Background(), chain, owner)
+}
@@ -4,20 +4,15 @@ package paprikahelpers
 
 import (
 	"context"
-	"crypto/md5"
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
-	"io"
 	"math"
 	"net/http"
-	"net/url"
 	"os"
-	"regexp"
 	"sort"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/olekukonko/tablewriter"
@@ -40,10 +35,6 @@ var (
 		Timeout: DefaultTimeout,
 	}
 
-	// cache stores API responses
-	cache    = make(map[string]CacheEntry)
-	cacheMux sync.RWMutex
-
 	// cacheDir stores the cache directory path
 	cacheDir = ".cache"
 )
@@ -59,275 +50,62 @@ func init() {
 
 // APIRequest makes an HTTP request to the DexPaprika API with caching and error handling
 func APIRequest(endpoint string, params map[string]string) (interface{}, error) {
-	// Create cache key
-	cacheKey := createCacheKey(endpoint, params)
-
-	// Check cache
-	cacheMux.RLock()
-	if entry, exists := cache[cacheKey]; exists && time.Since(entry.Timestamp) < CacheDuration {
-		cacheMux.RUnlock()
-		return entry.Data, nil
-	}
-	cacheMux.RUnlock()
-
-	// Build URL
-	u, err := url.Parse(BaseURL + endpoint)
-	if err != nil {
-		return nil, fmt.Errorf("invalid endpoint: %w", err)
-	}
-
-	// Add query parameters
-	q := u.Query()
-	for key, value := range params {
-		q.Add(key, value)
-	}
-	u.RawQuery = q.Encode()
-
-	// Make request
-	resp, err := httpClient.Get(u.String())
-	if err != nil {
-		return nil, fmt.Errorf("API request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// Read response
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
-	}
-
-	// Check for API errors
-	if resp.StatusCode >= 400 {
-		var apiErr APIError
-		if json.Unmarshal(body, &apiErr) == nil {
-			return nil, fmt.Errorf("API error: %s", apiErr.Error)
-		}
-		return nil, fmt.Errorf("API error: %s", string(body))
-	}
-
-	// Parse JSON
-	var result interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON: %w", err)
-	}
-
-	// Store in cache
-	cacheMux.Lock()
-	cache[cacheKey] = CacheEntry{
-		Data:      result,
-		Timestamp: time.Now(),
-	}
-	cacheMux.Unlock()
-
-	return result, nil
+	return APIRequestCtx(context.Background(), endpoint, params)
 }
 
 // GetNetworks retrieves all supported blockchain networks
 func GetNetworks() ([]Network, error) {
-	data, err := APIRequest("/networks", nil)
-	if err != nil {
-		return nil, err
-	}
-
-	var networks []Network
-	jsonBytes, _ := json.Marshal(data)
-	if err := json.Unmarshal(jsonBytes, &networks); err != nil {
-		return nil, fmt.Errorf("failed to parse networks: %w", err)
-	}
-
-	return networks, nil
+	return GetNetworksCtx(context.Background())
 }
 
 // GetNetworkPools retrieves pools for a specific network
 func GetNetworkPools(network string, params map[string]string) (*PoolsResponse, error) {
-	if params == nil {
-		params = make(map[string]string)
-	}
-
-	data, err := APIRequest(fmt.Sprintf("/networks/%s/pools", network), params)
-	if err != nil {
-		return nil, err
-	}
-
-	var response PoolsResponse
-	jsonBytes, _ := json.Marshal(data)
-	if err := json.Unmarshal(jsonBytes, &response); err != nil {
-		return nil, fmt.Errorf("failed to parse pools response: %w", err)
-	}
-
-	return &response, nil
+	return GetNetworkPoolsCtx(context.Background(), network, params)
 }
 
 // GetDexPools retrieves pools for a specific DEX on a network
 func GetDexPools(network, dex string, params map[string]string) (*PoolsResponse, error) {
-	if params == nil {
-		params = make(map[string]string)
-	}
-
-	data, err := APIRequest(fmt.Sprintf("/networks/%s/dexes/%s/pools", network, dex), params)
-	if err != nil {
-		return nil, err
-	}
-
-	var response PoolsResponse
-	jsonBytes, _ := json.Marshal(data)
-	if err := json.Unmarshal(jsonBytes, &response); err != nil {
-		return nil, fmt.Errorf("failed to parse dex pools response: %w", err)
-	}
-
-	return &response, nil
+	return GetDexPoolsCtx(context.Background(), network, dex, params)
 }
 
 // GetNetworkDexes retrieves DEXes available on a network
 func GetNetworkDexes(network string, params map[string]string) (*DexesResponse, error) {
-	if params == nil {
-		params = make(map[string]string)
-	}
-
-	data, err := APIRequest(fmt.Sprintf("/networks/%s/dexes", network), params)
-	if err != nil {
-		return nil, err
-	}
-
-	var response DexesResponse
-	jsonBytes, _ := json.Marshal(data)
-	if err := json.Unmarshal(jsonBytes, &response); err != nil {
-		return nil, fmt.Errorf("failed to parse dexes response: %w", err)
-	}
-
-	return &response, nil
+	return GetNetworkDexesCtx(context.Background(), network, params)
 }
 
 // GetPoolDetails retrieves detailed information about a specific pool
 func GetPoolDetails(network, poolAddress string, inversed bool) (*Pool, error) {
-	params := map[string]string{}
-	if inversed {
-		params["inversed"] = "true"
-	}
-
-	data, err := APIRequest(fmt.Sprintf("/networks/%s/pools/%s", network, poolAddress), params)
-	if err != nil {
-		return nil, err
-	}
-
-	var pool Pool
-	jsonBytes, _ := json.Marshal(data)
-	if err := json.Unmarshal(jsonBytes, &pool); err != nil {
-		return nil, fmt.Errorf("failed to parse pool details: %w", err)
-	}
-
-	return &pool, nil
+	return GetPoolDetailsCtx(context.Background(), network, poolAddress, inversed)
 }
 
 // GetPoolOHLCV retrieves OHLCV data for a pool
 func GetPoolOHLCV(network, poolAddress, start string, params map[string]string) ([]OHLCVRecord, error) {
-	if params == nil {
-		params = make(map[string]string)
-	}
-	params["start"] = start
-
-	data, err := APIRequest(fmt.Sprintf("/networks/%s/pools/%s/ohlcv", network, poolAddress), params)
-	if err != nil {
-		return nil, err
-	}
-
-	var records []OHLCVRecord
-	jsonBytes, _ := json.Marshal(data)
-	if err := json.Unmarshal(jsonBytes, &records); err != nil {
-		return nil, fmt.Errorf("failed to parse OHLCV data: %w", err)
-	}
-
-	return records, nil
+	return GetPoolOHLCVCtx(context.Background(), network, poolAddress, start, params)
 }
 
 // GetPoolTransactions retrieves transactions for a specific pool
 func GetPoolTransactions(network, poolAddress string, params map[string]string) (*TransactionsResponse, error) {
-	if params == nil {
-		params = make(map[string]string)
-	}
-
-	data, err := APIRequest(fmt.Sprintf("/networks/%s/pools/%s/transactions", network, poolAddress), params)
-	if err != nil {
-		return nil, err
-	}
-
-	var response TransactionsResponse
-	jsonBytes, _ := json.Marshal(data)
-	if err := json.Unmarshal(jsonBytes, &response); err != nil {
-		return nil, fmt.Errorf("failed to parse transactions response: %w", err)
-	}
-
-	return &response, nil
+	return GetPoolTransactionsCtx(context.Background(), network, poolAddress, params)
 }
 
 // GetTokenDetails retrieves detailed information about a specific token
 func GetTokenDetails(network, tokenAddress string) (*Token, error) {
-	data, err := APIRequest(fmt.Sprintf("/networks/%s/tokens/%s", network, tokenAddress), nil)
-	if err != nil {
-		return nil, err
-	}
-
-	var token Token
-	jsonBytes, _ := json.Marshal(data)
-	if err := json.Unmarshal(jsonBytes, &token); err != nil {
-		return nil, fmt.Errorf("failed to parse token details: %w", err)
-	}
-
-	return &token, nil
+	return GetTokenDetailsCtx(context.Background(), network, tokenAddress)
 }
 
 // GetTokenPools retrieves pools containing a specific token
 func GetTokenPools(network, tokenAddress string, params map[string]string) (*TokenPoolsResponse, error) {
-	if params == nil {
-		params = make(map[string]string)
-	}
-
-	data, err := APIRequest(fmt.Sprintf("/networks/%s/tokens/%s/pools", network, tokenAddress), params)
-	if err != nil {
-		return nil, err
-	}
-
-	var response TokenPoolsResponse
-	jsonBytes, _ := json.Marshal(data)
-	if err := json.Unmarshal(jsonBytes, &response); err != nil {
-		return nil, fmt.Errorf("failed to parse token pools response: %w", err)
-	}
-
-	return &response, nil
+	return GetTokenPoolsCtx(context.Background(), network, tokenAddress, params)
 }
 
 // SearchEntities searches across tokens, pools, and DEXes
 func SearchEntities(query string) (*SearchResponse, error) {
-	params := map[string]string{"query": query}
-
-	data, err := APIRequest("/search", params)
-	if err != nil {
-		return nil, err
-	}
-
-	var response SearchResponse
-	jsonBytes, _ := json.Marshal(data)
-	if err := json.Unmarshal(jsonBytes, &response); err != nil {
-		return nil, fmt.Errorf("failed to parse search response: %w", err)
-	}
-
-	return &response, nil
+	return SearchEntitiesCtx(context.Background(), query)
 }
 
 // GetSystemStats retrieves high-level system statistics
 func GetSystemStats() (*SystemStats, error) {
-	data, err := APIRequest("/stats", nil)
-	if err != nil {
-		return nil, err
-	}
-
-	var stats SystemStats
-	jsonBytes, _ := json.Marshal(data)
-	if err := json.Unmarshal(jsonBytes, &stats); err != nil {
-		return nil, fmt.Errorf("failed to parse system stats: %w", err)
-	}
-
-	return &stats, nil
+	return GetSystemStatsCtx(context.Background())
 }
 
 // ============================================================================
@@ -668,6 +446,13 @@ func ExtractOHLCVMetrics(data interface{}) map[string]interface{} {
 		metrics["volatility"] = CalculateVolatility(records)
 	}
 
+	// Plug in the common technical-indicator set now that we have the
+	// records in hand; each indicator zero-fills its own warmup window
+	// (including MACD, which returns all-zero series rather than
+	// slicing past the end for inputs shorter than its slow period), so
+	// there's no minimum length to gate on here.
+	metrics["indicators"] = Indicators(records)
+
 	return metrics
 }
 
@@ -1317,6 +1102,15 @@ func AnalyzeTransactionPatterns(transactions []Transaction) map[string]interface
 
 // FormatNumber formats a number with thousand separators
 func FormatNumber(num float64, decimals int) string {
+	switch {
+	case math.IsNaN(num):
+		return "NaN"
+	case math.IsInf(num, 1):
+		return "+Inf"
+	case math.IsInf(num, -1):
+		return "-Inf"
+	}
+
 	format := fmt.Sprintf("%%.%df", decimals)
 	str := fmt.Sprintf(format, num)
 
@@ -1457,23 +1251,6 @@ func ValidateNetwork(network string) (bool, error) {
 	return false, nil
 }
 
-// ValidateTokenAddress validates if a token address format is correct
-func ValidateTokenAddress(address string) bool {
-	// Ethereum address pattern (0x followed by 40 hex characters)
-	ethPattern := `^0x[a-fA-F0-9]{40}$`
-	if matched, _ := regexp.MatchString(ethPattern, address); matched {
-		return true
-	}
-
-	// Solana address pattern (base58, 32-44 characters)
-	solanaPattern := `^[1-9A-HJ-NP-Za-km-z]{32,44}$`
-	if matched, _ := regexp.MatchString(solanaPattern, address); matched {
-		return true
-	}
-
-	return false
-}
-
 // CreateTimestamp creates an ISO timestamp for days ago
 func CreateTimestamp(daysAgo int) string {
 	t := time.Now().AddDate(0, 0, -daysAgo)
@@ -1608,12 +1385,102 @@ func AnalyzeDexDistribution(pools []Pool) DexDistribution {
 	}
 
 	return DexDistribution{
-		TotalVolume:   totalVolume,
-		DexCount:      len(dexVolumes),
-		Distribution:  distribution,
-		TopDexes:      topDexes,
-		Concentration: concentration,
+		TotalVolume:        totalVolume,
+		DexCount:           len(dexVolumes),
+		Distribution:       distribution,
+		TopDexes:           topDexes,
+		Concentration:      concentration,
+		ConcentrationClass: classifyConcentration(concentration),
+		EffectiveN:         effectiveN(concentration),
+		CR4:                concentrationRatio(topDexes, distribution, 4),
+		CR8:                concentrationRatio(topDexes, distribution, 8),
+		RosenbluthIndex:    rosenbluthIndex(topDexes, distribution),
+	}
+}
+
+// classifyConcentration labels an HHI value (on the 0..1 scale) using the
+// standard antitrust breakpoints: below 0.15 is "Unconcentrated", 0.15 to
+// 0.25 is "Moderate", above 0.25 is "Highly Concentrated".
+func classifyConcentration(hhi float64) string {
+	switch {
+	case hhi > 0.25:
+		return "Highly Concentrated"
+	case hhi >= 0.15:
+		return "Moderate"
+	default:
+		return "Unconcentrated"
+	}
+}
+
+// effectiveN is the numbers-equivalent of an HHI value: the number of
+// equally sized competitors that would produce the same concentration.
+func effectiveN(hhi float64) float64 {
+	if hhi == 0 {
+		return 0
+	}
+	return 1 / hhi
+}
+
+// concentrationRatio sums the volume share of the top k DEXes in
+// rankedDexes (already sorted by descending volume), returning 0 if fewer
+// than k DEXes are present.
+func concentrationRatio(rankedDexes []string, distribution map[string]float64, k int) float64 {
+	if len(rankedDexes) < k {
+		return 0
+	}
+	var sum float64
+	for _, dex := range rankedDexes[:k] {
+		sum += distribution[dex]
+	}
+	return sum
+}
+
+// rosenbluthIndex computes the Rosenbluth (Hall-Tideman) concentration
+// index, an alternative to HHI that weights each competitor's share by its
+// rank: 1 / (2*Σ(i·pᵢ) - 1), where pᵢ are volume shares ranked descending
+// and i starts at 1.
+func rosenbluthIndex(rankedDexes []string, distribution map[string]float64) float64 {
+	var weighted float64
+	for i, dex := range rankedDexes {
+		weighted += float64(i+1) * distribution[dex]
+	}
+	denominator := 2*weighted - 1
+	if denominator <= 0 {
+		return 0
 	}
+	return 1 / denominator
+}
+
+// AnalyzeDexDistributionOverTime buckets pools by CreatedAt into intervals
+// of bucket (e.g. 24*time.Hour) and runs AnalyzeDexDistribution on each
+// bucket's pools, so callers can chart DEX concentration trends (e.g.
+// consolidation) over time. Pools whose CreatedAt doesn't parse as
+// RFC3339 are skipped. The result is sorted by BucketTime ascending.
+func AnalyzeDexDistributionOverTime(pools []Pool, bucket time.Duration) []DexDistributionSnapshot {
+	byBucket := make(map[time.Time][]Pool)
+
+	for _, pool := range pools {
+		t, err := time.Parse(time.RFC3339, pool.CreatedAt)
+		if err != nil {
+			continue
+		}
+		b := t.Truncate(bucket)
+		byBucket[b] = append(byBucket[b], pool)
+	}
+
+	snapshots := make([]DexDistributionSnapshot, 0, len(byBucket))
+	for b, bucketPools := range byBucket {
+		snapshots = append(snapshots, DexDistributionSnapshot{
+			BucketTime:      b,
+			DexDistribution: AnalyzeDexDistribution(bucketPools),
+		})
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].BucketTime.Before(snapshots[j].BucketTime)
+	})
+
+	return snapshots
 }
 
 // GetMarketOverview retrieves comprehensive market overview
@@ -1677,86 +1544,39 @@ func AsyncAPIRequest(ctx context.Context, endpoint string, params map[string]str
 	}
 }
 
-// AsyncGetMultiplePools gets pools from multiple networks concurrently
+// AsyncGetMultiplePools gets pools from multiple networks concurrently,
+// bounding in-flight requests to the default async WorkerPool's
+// concurrency and rate limit instead of spawning one goroutine per
+// network (see SetAsyncWorkerPool to tune this).
 func AsyncGetMultiplePools(ctx context.Context, networks []string, limit int) (map[string]interface{}, error) {
-	results := make(map[string]interface{})
-	resultsChan := make(chan struct {
-		network string
-		data    interface{}
-		err     error
-	}, len(networks))
-
-	// Start goroutines for each network
-	for _, network := range networks {
-		go func(net string) {
-			params := map[string]string{"limit": strconv.Itoa(limit)}
-			data, err := AsyncAPIRequest(ctx, fmt.Sprintf("/networks/%s/pools", net), params)
-			resultsChan <- struct {
-				network string
-				data    interface{}
-				err     error
-			}{net, data, err}
-		}(network)
-	}
-
-	// Collect results
-	for i := 0; i < len(networks); i++ {
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		case result := <-resultsChan:
-			if result.err != nil {
-				results[result.network] = map[string]string{"error": result.err.Error()}
-			} else {
-				results[result.network] = result.data
-			}
+	pool := currentAsyncWorkerPool()
+	rows := pool.Map(ctx, networks, func(taskCtx context.Context, network string) (interface{}, error) {
+		params := map[string]string{"limit": strconv.Itoa(limit)}
+		return AsyncAPIRequest(taskCtx, fmt.Sprintf("/networks/%s/pools", network), params)
+	})
+
+	results := make(map[string]interface{}, len(networks))
+	for i, network := range networks {
+		if rows[i].Error != "" {
+			results[network] = map[string]string{"error": rows[i].Error}
+		} else {
+			results[network] = rows[i].Data
 		}
 	}
 
 	return results, nil
 }
 
-// AsyncGetTokenDataBatch gets data for multiple tokens concurrently
+// AsyncGetTokenDataBatch gets data for multiple tokens concurrently,
+// bounding in-flight requests to the default async WorkerPool's
+// concurrency and rate limit instead of spawning one goroutine per token
+// (see SetAsyncWorkerPool to tune this). A batch of thousands of
+// addresses is paced and retried rather than fanned out unbounded.
 func AsyncGetTokenDataBatch(ctx context.Context, tokenAddresses []string, network string) ([]AsyncResult, error) {
-	results := make([]AsyncResult, len(tokenAddresses))
-	resultsChan := make(chan struct {
-		index int
-		data  interface{}
-		err   error
-	}, len(tokenAddresses))
-
-	// Start goroutines for each token
-	for i, address := range tokenAddresses {
-		go func(idx int, addr string) {
-			data, err := AsyncAPIRequest(ctx, fmt.Sprintf("/networks/%s/tokens/%s", network, addr), nil)
-			resultsChan <- struct {
-				index int
-				data  interface{}
-				err   error
-			}{idx, data, err}
-		}(i, address)
-	}
-
-	// Collect results
-	for i := 0; i < len(tokenAddresses); i++ {
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		case result := <-resultsChan:
-			if result.err != nil {
-				results[result.index] = AsyncResult{
-					Data:  nil,
-					Error: result.err.Error(),
-				}
-			} else {
-				results[result.index] = AsyncResult{
-					Data:  result.data,
-					Error: "",
-				}
-			}
-		}
-	}
-
+	pool := currentAsyncWorkerPool()
+	results := pool.Map(ctx, tokenAddresses, func(taskCtx context.Context, address string) (interface{}, error) {
+		return AsyncAPIRequest(taskCtx, fmt.Sprintf("/networks/%s/tokens/%s", network, address), nil)
+	})
 	return results, nil
 }
 
@@ -1789,50 +1609,19 @@ func AsyncMonitorPrices(ctx context.Context, poolAddresses []string, network str
 	}
 }
 
-// AsyncBatchSearch performs multiple searches concurrently
+// AsyncBatchSearch performs multiple searches concurrently, bounding
+// in-flight requests to the default async WorkerPool's concurrency and
+// rate limit instead of spawning one goroutine per query (see
+// SetAsyncWorkerPool to tune this).
 func AsyncBatchSearch(ctx context.Context, queries []string) ([]AsyncResult, error) {
-	results := make([]AsyncResult, len(queries))
-	resultsChan := make(chan struct {
-		index int
-		query string
-		data  interface{}
-		err   error
-	}, len(queries))
-
-	// Start goroutines for each query
+	pool := currentAsyncWorkerPool()
+	results := pool.Map(ctx, queries, func(taskCtx context.Context, query string) (interface{}, error) {
+		params := map[string]string{"query": query}
+		return AsyncAPIRequest(taskCtx, "/search", params)
+	})
+
 	for i, query := range queries {
-		go func(idx int, q string) {
-			params := map[string]string{"query": q}
-			data, err := AsyncAPIRequest(ctx, "/search", params)
-			resultsChan <- struct {
-				index int
-				query string
-				data  interface{}
-				err   error
-			}{idx, q, data, err}
-		}(i, query)
-	}
-
-	// Collect results
-	for i := 0; i < len(queries); i++ {
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		case result := <-resultsChan:
-			if result.err != nil {
-				results[result.index] = AsyncResult{
-					Query: result.query,
-					Data:  nil,
-					Error: result.err.Error(),
-				}
-			} else {
-				results[result.index] = AsyncResult{
-					Query: result.query,
-					Data:  result.data,
-					Error: "",
-				}
-			}
-		}
+		results[i].Query = query
 	}
 
 	return results, nil
@@ -1842,18 +1631,37 @@ func AsyncBatchSearch(ctx context.Context, queries []string) ([]AsyncResult, err
 // UTILITY FUNCTIONS
 // ============================================================================
 
-// createCacheKey creates a cache key from endpoint and parameters
+// createCacheKey creates a cache key from endpoint and parameters. Params
+// are sorted so equivalent requests with differently-ordered params always
+// collapse to the same key, then narrowed to endpoint's CachePolicy.Vary
+// list (if any) before hashing, so params that don't actually affect the
+// response (e.g. an auth token) don't fragment the cache. The resulting
+// key is recorded against endpoint so Cache.Invalidate can later evict it
+// by pattern.
 func createCacheKey(endpoint string, params map[string]string) string {
+	policy := cachePolicyFor(endpoint)
+
 	key := endpoint
 	if params != nil {
+		filtered := params
+		if len(policy.Vary) > 0 {
+			filtered = make(map[string]string, len(policy.Vary))
+			for _, name := range policy.Vary {
+				if v, ok := params[name]; ok {
+					filtered[name] = v
+				}
+			}
+		}
+
 		var keys []string
-		for k, v := range params {
+		for k, v := range filtered {
 			keys = append(keys, fmt.Sprintf("%s=%s", k, v))
 		}
 		sort.Strings(keys)
 		key += "?" + strings.Join(keys, "&")
 	}
 
-	hash := md5.Sum([]byte(key))
-	return fmt.Sprintf("%x", hash)
+	hashedKey := hashCacheKey(key)
+	recordCacheKeyPath(hashedKey, endpoint)
+	return hashedKey
 }
@@ -0,0 +1,359 @@
+package paprikahelpers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// ============================================================================
+// PRICE STREAM (WebSocket, falling back to HTTP polling)
+// ============================================================================
+
+// PriceTick is a single price observation for a pool.
+type PriceTick struct {
+	PoolID    string    `json:"pool_id"`
+	PriceUSD  float64   `json:"price_usd"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// SwapEvent is a single swap observed on a pool.
+type SwapEvent struct {
+	PoolID    string    `json:"pool_id"`
+	AmountIn  float64   `json:"amount_in"`
+	AmountOut float64   `json:"amount_out"`
+	TokenIn   string    `json:"token_in"`
+	TokenOut  string    `json:"token_out"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// LiquidityChange is a liquidity add/remove observed on a pool.
+type LiquidityChange struct {
+	PoolID       string    `json:"pool_id"`
+	LiquidityUSD float64   `json:"liquidity_usd"`
+	Timestamp    time.Time `json:"timestamp"`
+}
+
+// wireMessage is the subset of fields PriceStream cares about across every
+// message type the server can send, decoded once and dispatched by Type.
+type wireMessage struct {
+	Type         string  `json:"type"`
+	PoolID       string  `json:"pool_id"`
+	PriceUSD     float64 `json:"price_usd"`
+	AmountIn     float64 `json:"amount_in"`
+	AmountOut    float64 `json:"amount_out"`
+	TokenIn      string  `json:"token_in"`
+	TokenOut     string  `json:"token_out"`
+	LiquidityUSD float64 `json:"liquidity_usd"`
+	ResumeToken  string  `json:"resume_token"`
+}
+
+// poolSubs is the per-pool fan-out state: every channel handed out by
+// Subscribe for that pool, plus the resume token the server last sent for
+// it (replayed on resubscribe after a reconnect).
+type poolSubs struct {
+	ticks       []chan PriceTick
+	swaps       []chan SwapEvent
+	liquidity   []chan LiquidityChange
+	resumeToken string
+	// fallbackStarted marks that runHTTPFallback is already polling this
+	// pool, so additional subscribers to the same pool don't each spawn
+	// their own AsyncMonitorPrices poller.
+	fallbackStarted bool
+}
+
+// PriceStream dials a WebSocket endpoint, subscribes to pool channels, and
+// dispatches typed events (PriceTick, SwapEvent, LiquidityChange) to every
+// registered subscriber, multiplexing all of them over one connection with
+// automatic reconnect and per-pool resume tokens. If Endpoint is empty, the
+// handshake fails, or the server responds with anything other than a
+// protocol upgrade, it falls back to the HTTP ticker path (AsyncMonitorPrices)
+// instead of erroring out.
+type PriceStream struct {
+	// Endpoint is a ws:// or wss:// URL. Empty disables WebSocket and goes
+	// straight to HTTP polling.
+	Endpoint string
+	// Network is the chain passed to the HTTP fallback path.
+	Network string
+	// PollInterval is the HTTP fallback's polling period. Defaults to 10s.
+	PollInterval time.Duration
+
+	mu            sync.Mutex
+	conn          *websocket.Conn
+	pools         map[string]*poolSubs
+	wsUnsupported bool
+	connecting    bool
+}
+
+// NewPriceStream builds a PriceStream for network, dialing endpoint for
+// live updates. Pass an empty endpoint to always use the HTTP fallback.
+func NewPriceStream(endpoint, network string) *PriceStream {
+	return &PriceStream{
+		Endpoint: endpoint,
+		Network:  network,
+		pools:    make(map[string]*poolSubs),
+	}
+}
+
+// Subscribe returns a channel of PriceTick events for poolID, starting the
+// underlying connection (or HTTP fallback poller) if this is the first
+// subscriber for any pool.
+func (p *PriceStream) Subscribe(ctx context.Context, poolID string) (<-chan PriceTick, error) {
+	ch := make(chan PriceTick, 16)
+
+	p.mu.Lock()
+	subs := p.pools[poolID]
+	firstForPool := subs == nil
+	if firstForPool {
+		subs = &poolSubs{}
+		p.pools[poolID] = subs
+	}
+	subs.ticks = append(subs.ticks, ch)
+	p.mu.Unlock()
+
+	if err := p.ensureConnected(ctx); err != nil {
+		return nil, err
+	}
+
+	if p.usingFallback() {
+		p.mu.Lock()
+		startFallback := !subs.fallbackStarted
+		subs.fallbackStarted = true
+		p.mu.Unlock()
+		if startFallback {
+			go p.runHTTPFallback(ctx, poolID)
+		}
+	} else if firstForPool {
+		p.sendSubscribe(poolID, "")
+	}
+
+	return ch, nil
+}
+
+// usingFallback reports whether PriceStream has given up on WebSocket and
+// is serving subscribers via HTTP polling instead.
+func (p *PriceStream) usingFallback() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.Endpoint == "" || p.wsUnsupported
+}
+
+// ensureConnected dials Endpoint if not already connected (or already
+// marked unsupported), starting the read loop on success. A failed dial
+// marks wsUnsupported so callers fall back to polling instead of retrying
+// forever inline; reconnect (called from readLoop) is what
+// keeps retrying a connection that *was* established and then dropped.
+func (p *PriceStream) ensureConnected(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.Endpoint == "" || p.wsUnsupported || p.conn != nil || p.connecting {
+		return nil
+	}
+	p.connecting = true
+
+	conn, resp, err := websocket.DefaultDialer.DialContext(ctx, p.Endpoint, nil)
+	p.connecting = false
+	if err != nil {
+		p.wsUnsupported = true
+		if resp != nil {
+			resp.Body.Close()
+		}
+		return nil
+	}
+
+	p.conn = conn
+	go p.readLoop(ctx)
+	return nil
+}
+
+// sendSubscribe sends a subscribe frame for poolID, optionally resuming
+// from resumeToken.
+func (p *PriceStream) sendSubscribe(poolID, resumeToken string) {
+	p.mu.Lock()
+	conn := p.conn
+	p.mu.Unlock()
+	if conn == nil {
+		return
+	}
+
+	frame := map[string]string{
+		"type":    "subscribe",
+		"channel": "pool",
+		"pool_id": poolID,
+	}
+	if resumeToken != "" {
+		frame["resume_token"] = resumeToken
+	}
+
+	_ = conn.WriteJSON(frame)
+}
+
+// readLoop decodes incoming messages and dispatches them until the
+// connection drops, at which point it attempts to reconnect with
+// exponential backoff and resume every active subscription from its last
+// known resume token. It gives up and falls back to HTTP polling after
+// repeated reconnect failures.
+func (p *PriceStream) readLoop(ctx context.Context) {
+	for {
+		p.mu.Lock()
+		conn := p.conn
+		p.mu.Unlock()
+		if conn == nil {
+			return
+		}
+
+		var msg wireMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			conn.Close()
+
+			p.mu.Lock()
+			p.conn = nil
+			p.mu.Unlock()
+
+			if !p.reconnect(ctx) {
+				return
+			}
+			continue
+		}
+
+		p.dispatch(msg)
+	}
+}
+
+// reconnect redials Endpoint with exponential backoff, giving up (and
+// leaving wsUnsupported set so subsequent Subscribe calls use the HTTP
+// fallback) after 5 failed attempts. On success it resubscribes every
+// pool with an active subscriber, passing along that pool's last resume
+// token.
+func (p *PriceStream) reconnect(ctx context.Context) bool {
+	backoff := 500 * time.Millisecond
+	const maxBackoff = 30 * time.Second
+
+	for attempt := 0; attempt < 5; attempt++ {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(backoff):
+		}
+
+		conn, resp, err := websocket.DefaultDialer.DialContext(ctx, p.Endpoint, nil)
+		if err == nil {
+			p.mu.Lock()
+			p.conn = conn
+			pools := make(map[string]string, len(p.pools))
+			for poolID, subs := range p.pools {
+				pools[poolID] = subs.resumeToken
+			}
+			p.mu.Unlock()
+
+			for poolID, resumeToken := range pools {
+				p.sendSubscribe(poolID, resumeToken)
+			}
+			return true
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		backoff = nextBackoff(backoff, maxBackoff)
+	}
+
+	p.mu.Lock()
+	p.wsUnsupported = true
+	pools := make([]string, 0, len(p.pools))
+	for poolID, subs := range p.pools {
+		if !subs.fallbackStarted {
+			subs.fallbackStarted = true
+			pools = append(pools, poolID)
+		}
+	}
+	p.mu.Unlock()
+
+	for _, poolID := range pools {
+		go p.runHTTPFallback(ctx, poolID)
+	}
+	return false
+}
+
+// dispatch fans a decoded wire message out to every subscriber of its
+// pool, updating that pool's resume token along the way. The subscriber
+// slice for msg.Type is snapshotted under p.mu before sending, since
+// Subscribe appends to that same slice under the same lock — ranging over
+// it after unlocking would race against a concurrent append.
+func (p *PriceStream) dispatch(msg wireMessage) {
+	p.mu.Lock()
+	subs, ok := p.pools[msg.PoolID]
+	if !ok {
+		p.mu.Unlock()
+		return
+	}
+	if msg.ResumeToken != "" {
+		subs.resumeToken = msg.ResumeToken
+	}
+
+	var ticks []chan PriceTick
+	var swaps []chan SwapEvent
+	var liquidity []chan LiquidityChange
+	switch msg.Type {
+	case "price_tick":
+		ticks = append(ticks, subs.ticks...)
+	case "swap":
+		swaps = append(swaps, subs.swaps...)
+	case "liquidity_change":
+		liquidity = append(liquidity, subs.liquidity...)
+	}
+	p.mu.Unlock()
+
+	now := time.Now()
+	switch msg.Type {
+	case "price_tick":
+		tick := PriceTick{PoolID: msg.PoolID, PriceUSD: msg.PriceUSD, Timestamp: now}
+		for _, ch := range ticks {
+			select {
+			case ch <- tick:
+			default:
+			}
+		}
+	case "swap":
+		evt := SwapEvent{
+			PoolID:    msg.PoolID,
+			AmountIn:  msg.AmountIn,
+			AmountOut: msg.AmountOut,
+			TokenIn:   msg.TokenIn,
+			TokenOut:  msg.TokenOut,
+			Timestamp: now,
+		}
+		for _, ch := range swaps {
+			select {
+			case ch <- evt:
+			default:
+			}
+		}
+	case "liquidity_change":
+		evt := LiquidityChange{PoolID: msg.PoolID, LiquidityUSD: msg.LiquidityUSD, Timestamp: now}
+		for _, ch := range liquidity {
+			select {
+			case ch <- evt:
+			default:
+			}
+		}
+	}
+}
+
+// runHTTPFallback serves poolID's PriceTick subscribers by polling
+// GetPoolDetailsCtx on PollInterval, via the existing AsyncMonitorPrices
+// ticker loop, until ctx is canceled.
+func (p *PriceStream) runHTTPFallback(ctx context.Context, poolID string) {
+	interval := p.PollInterval
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+
+	_ = AsyncMonitorPrices(ctx, []string{poolID}, p.Network, interval, func(addr string, update map[string]interface{}) {
+		price, _ := update["price_usd"].(float64)
+		p.dispatch(wireMessage{Type: "price_tick", PoolID: addr, PriceUSD: price})
+	})
+}
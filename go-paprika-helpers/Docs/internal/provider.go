@@ -0,0 +1,391 @@
+package paprikahelpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// ============================================================================
+// PLUGGABLE DATA PROVIDER FALLBACK (price/pool/OHLCV/search across sources)
+// ============================================================================
+
+// DataProvider is a broader upstream source than DataSource (see
+// datasource.go): in addition to pool listings it can quote a single
+// token's price, fetch OHLCV candles, and run a free-text search, all
+// normalized into the existing Token, Pool, OHLCVRecord, and
+// SearchResponse structs so downstream analysis functions work unchanged
+// regardless of which provider actually answered.
+type DataProvider interface {
+	// Name identifies the provider for logging/error messages.
+	Name() string
+
+	GetTokenPrice(network, tokenAddress string) (*Token, error)
+	GetNetworkPools(network string, params map[string]string) ([]Pool, error)
+	GetOHLCV(network, poolAddress, start string, params map[string]string) ([]OHLCVRecord, error)
+	Search(query string) (*SearchResponse, error)
+}
+
+// paprikaProvider is the default DataProvider, backed by the existing
+// DexPaprika client functions.
+type paprikaProvider struct{}
+
+func (paprikaProvider) Name() string { return "dexpaprika" }
+
+func (paprikaProvider) GetTokenPrice(network, tokenAddress string) (*Token, error) {
+	return GetTokenDetails(network, tokenAddress)
+}
+
+func (paprikaProvider) GetNetworkPools(network string, params map[string]string) ([]Pool, error) {
+	resp, err := GetNetworkPools(network, params)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Pools, nil
+}
+
+func (paprikaProvider) GetOHLCV(network, poolAddress, start string, params map[string]string) ([]OHLCVRecord, error) {
+	return GetPoolOHLCV(network, poolAddress, start, params)
+}
+
+func (paprikaProvider) Search(query string) (*SearchResponse, error) {
+	return SearchEntities(query)
+}
+
+// DefaultProvider is the DexPaprika-backed DataProvider, exported so
+// callers can include it explicitly in a FallbackClient's priority list
+// alongside CoinGeckoProvider/CoinMarketCapProvider.
+var DefaultProvider DataProvider = paprikaProvider{}
+
+// ----------------------------------------------------------------------------
+// CoinGecko
+// ----------------------------------------------------------------------------
+
+// CoinGeckoProvider is a DataProvider backed by CoinGecko's public
+// on-chain DEX API (no API key required).
+type CoinGeckoProvider struct {
+	// BaseURL defaults to the public CoinGecko API if empty.
+	BaseURL string
+}
+
+func (p CoinGeckoProvider) Name() string { return "coingecko" }
+
+func (p CoinGeckoProvider) baseURL() string {
+	if p.BaseURL != "" {
+		return p.BaseURL
+	}
+	return "https://api.coingecko.com/api/v3/onchain"
+}
+
+func (p CoinGeckoProvider) GetTokenPrice(network, tokenAddress string) (*Token, error) {
+	u := fmt.Sprintf("%s/networks/%s/tokens/%s", p.baseURL(), network, tokenAddress)
+
+	var payload struct {
+		Data struct {
+			Attributes struct {
+				Address     string `json:"address"`
+				Name        string `json:"name"`
+				Symbol      string `json:"symbol"`
+				Decimals    int    `json:"decimals"`
+				PriceUSD    string `json:"price_usd"`
+				FDV         string `json:"fdv_usd"`
+				TotalSupply string `json:"total_supply"`
+			} `json:"attributes"`
+		} `json:"data"`
+	}
+	if err := coinGeckoGet(u, &payload); err != nil {
+		return nil, err
+	}
+
+	priceUSD, _ := strconv.ParseFloat(payload.Data.Attributes.PriceUSD, 64)
+	fdv, _ := strconv.ParseFloat(payload.Data.Attributes.FDV, 64)
+	totalSupply, _ := strconv.ParseFloat(payload.Data.Attributes.TotalSupply, 64)
+
+	return &Token{
+		ID:          payload.Data.Attributes.Address,
+		Name:        payload.Data.Attributes.Name,
+		Symbol:      payload.Data.Attributes.Symbol,
+		Chain:       network,
+		Decimals:    payload.Data.Attributes.Decimals,
+		TotalSupply: totalSupply,
+		FDV:         fdv,
+		Summary:     &TokenSummary{PriceUSD: priceUSD, FDV: fdv},
+	}, nil
+}
+
+func (p CoinGeckoProvider) GetNetworkPools(network string, params map[string]string) ([]Pool, error) {
+	return CoinGeckoSource{BaseURL: p.BaseURL}.NetworkPools(network, params)
+}
+
+func (p CoinGeckoProvider) GetOHLCV(network, poolAddress, start string, params map[string]string) ([]OHLCVRecord, error) {
+	u := fmt.Sprintf("%s/networks/%s/pools/%s/ohlcv/day", p.baseURL(), network, poolAddress)
+
+	var payload struct {
+		Data struct {
+			Attributes struct {
+				OHLCVList [][]float64 `json:"ohlcv_list"`
+			} `json:"attributes"`
+		} `json:"data"`
+	}
+	if err := coinGeckoGet(u, &payload); err != nil {
+		return nil, err
+	}
+
+	records := make([]OHLCVRecord, 0, len(payload.Data.Attributes.OHLCVList))
+	for _, candle := range payload.Data.Attributes.OHLCVList {
+		if len(candle) < 6 {
+			continue
+		}
+		records = append(records, OHLCVRecord{
+			TimeOpen: fmt.Sprintf("%.0f", candle[0]),
+			Open:     candle[1],
+			High:     candle[2],
+			Low:      candle[3],
+			Close:    candle[4],
+			Volume:   int64(candle[5]),
+		})
+	}
+	return records, nil
+}
+
+func (p CoinGeckoProvider) Search(query string) (*SearchResponse, error) {
+	u := fmt.Sprintf("%s/search/pools?query=%s", p.baseURL(), url.QueryEscape(query))
+
+	var payload struct {
+		Data []struct {
+			Attributes struct {
+				Address      string `json:"address"`
+				Name         string `json:"name"`
+				VolumeUSD24h string `json:"volume_usd_h24"`
+			} `json:"attributes"`
+		} `json:"data"`
+	}
+	if err := coinGeckoGet(u, &payload); err != nil {
+		return nil, err
+	}
+
+	pools := make([]Pool, 0, len(payload.Data))
+	for _, d := range payload.Data {
+		volume, _ := strconv.ParseFloat(d.Attributes.VolumeUSD24h, 64)
+		pools = append(pools, Pool{
+			ID:        d.Attributes.Address,
+			DexName:   d.Attributes.Name,
+			VolumeUSD: volume,
+		})
+	}
+
+	return &SearchResponse{Pools: pools}, nil
+}
+
+// coinGeckoGet issues a GET against u and decodes the JSON body into out,
+// returning an "unavailable" error for a 5xx or 429 response and a plain
+// error for anything else >= 400 (FallbackClient moves on to the next
+// provider after either).
+func coinGeckoGet(u string, out interface{}) error {
+	resp, err := httpClient.Get(u)
+	if err != nil {
+		return fmt.Errorf("coingecko request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if isRetryableStatus(resp.StatusCode) {
+		return fmt.Errorf("coingecko unavailable: status %d", resp.StatusCode)
+	}
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("coingecko error: status %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to parse coingecko response: %w", err)
+	}
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+// CoinMarketCap Pro
+// ----------------------------------------------------------------------------
+
+// CoinMarketCapProvider is a DataProvider backed by the CoinMarketCap Pro
+// API. It reads its API key from the CMC_PRO_API_KEY environment variable
+// (via LoadEnv) unless APIKey is set explicitly. CoinMarketCap has no
+// on-chain pool/OHLCV endpoints on the free Pro tier, so GetNetworkPools,
+// GetOHLCV, and Search report an error rather than silently returning
+// nothing, allowing FallbackClient to fall through to the next provider.
+type CoinMarketCapProvider struct {
+	// BaseURL defaults to the public CoinMarketCap Pro API if empty.
+	BaseURL string
+	// APIKey overrides CMC_PRO_API_KEY when set.
+	APIKey string
+}
+
+func (p CoinMarketCapProvider) Name() string { return "coinmarketcap" }
+
+func (p CoinMarketCapProvider) baseURL() string {
+	if p.BaseURL != "" {
+		return p.BaseURL
+	}
+	return "https://pro-api.coinmarketcap.com/v2"
+}
+
+func (p CoinMarketCapProvider) apiKey() string {
+	if p.APIKey != "" {
+		return p.APIKey
+	}
+	return LoadEnv("CMC_PRO_API_KEY", "")
+}
+
+func (p CoinMarketCapProvider) GetTokenPrice(network, tokenAddress string) (*Token, error) {
+	key := p.apiKey()
+	if key == "" {
+		return nil, fmt.Errorf("coinmarketcap: CMC_PRO_API_KEY not set")
+	}
+
+	u := fmt.Sprintf("%s/cryptocurrency/quotes/latest?address=%s", p.baseURL(), url.QueryEscape(tokenAddress))
+
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint: %w", err)
+	}
+	req.Header.Set("X-CMC_PRO_API_KEY", key)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("coinmarketcap request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if isRetryableStatus(resp.StatusCode) {
+		return nil, fmt.Errorf("coinmarketcap unavailable: status %d", resp.StatusCode)
+	}
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("coinmarketcap error: status %d", resp.StatusCode)
+	}
+
+	var payload struct {
+		Data map[string]struct {
+			Name   string `json:"name"`
+			Symbol string `json:"symbol"`
+			Quote  struct {
+				USD struct {
+					Price              float64 `json:"price"`
+					FullyDilutedMktCap float64 `json:"fully_diluted_market_cap"`
+				} `json:"USD"`
+			} `json:"quote"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("failed to parse coinmarketcap response: %w", err)
+	}
+
+	for _, entry := range payload.Data {
+		return &Token{
+			ID:     tokenAddress,
+			Name:   entry.Name,
+			Symbol: entry.Symbol,
+			Chain:  network,
+			FDV:    entry.Quote.USD.FullyDilutedMktCap,
+			Summary: &TokenSummary{
+				PriceUSD: entry.Quote.USD.Price,
+				FDV:      entry.Quote.USD.FullyDilutedMktCap,
+			},
+		}, nil
+	}
+
+	return nil, fmt.Errorf("coinmarketcap: no data returned for %s", tokenAddress)
+}
+
+func (p CoinMarketCapProvider) GetNetworkPools(network string, params map[string]string) ([]Pool, error) {
+	return nil, fmt.Errorf("coinmarketcap: pool listings not supported")
+}
+
+func (p CoinMarketCapProvider) GetOHLCV(network, poolAddress, start string, params map[string]string) ([]OHLCVRecord, error) {
+	return nil, fmt.Errorf("coinmarketcap: OHLCV not supported")
+}
+
+func (p CoinMarketCapProvider) Search(query string) (*SearchResponse, error) {
+	return nil, fmt.Errorf("coinmarketcap: search not supported")
+}
+
+// ----------------------------------------------------------------------------
+// FallbackClient
+// ----------------------------------------------------------------------------
+
+// FallbackClient tries a priority-ordered list of DataProviders, moving on
+// to the next provider when one returns an error (including a timeout or
+// rate limit), so a DexPaprika outage transparently degrades to CoinGecko
+// or CoinMarketCap instead of failing the caller's request outright.
+type FallbackClient struct {
+	Providers []DataProvider
+}
+
+// NewFallbackClient builds a FallbackClient trying providers in the given
+// order. With no providers, it falls back to []DataProvider{DefaultProvider}.
+func NewFallbackClient(providers ...DataProvider) *FallbackClient {
+	if len(providers) == 0 {
+		providers = []DataProvider{DefaultProvider}
+	}
+	return &FallbackClient{Providers: providers}
+}
+
+// GetTokenPrice tries each provider in order, returning the first
+// successful result.
+func (f *FallbackClient) GetTokenPrice(network, tokenAddress string) (*Token, error) {
+	var lastErr error
+	for _, provider := range f.Providers {
+		token, err := provider.GetTokenPrice(network, tokenAddress)
+		if err != nil {
+			lastErr = fmt.Errorf("%s: %w", provider.Name(), err)
+			continue
+		}
+		return token, nil
+	}
+	return nil, fmt.Errorf("all providers failed, last error: %w", lastErr)
+}
+
+// GetNetworkPools tries each provider in order, returning the first
+// successful result.
+func (f *FallbackClient) GetNetworkPools(network string, params map[string]string) ([]Pool, error) {
+	var lastErr error
+	for _, provider := range f.Providers {
+		pools, err := provider.GetNetworkPools(network, params)
+		if err != nil {
+			lastErr = fmt.Errorf("%s: %w", provider.Name(), err)
+			continue
+		}
+		return pools, nil
+	}
+	return nil, fmt.Errorf("all providers failed, last error: %w", lastErr)
+}
+
+// GetOHLCV tries each provider in order, returning the first successful
+// result.
+func (f *FallbackClient) GetOHLCV(network, poolAddress, start string, params map[string]string) ([]OHLCVRecord, error) {
+	var lastErr error
+	for _, provider := range f.Providers {
+		records, err := provider.GetOHLCV(network, poolAddress, start, params)
+		if err != nil {
+			lastErr = fmt.Errorf("%s: %w", provider.Name(), err)
+			continue
+		}
+		return records, nil
+	}
+	return nil, fmt.Errorf("all providers failed, last error: %w", lastErr)
+}
+
+// Search tries each provider in order, returning the first successful
+// result.
+func (f *FallbackClient) Search(query string) (*SearchResponse, error) {
+	var lastErr error
+	for _, provider := range f.Providers {
+		response, err := provider.Search(query)
+		if err != nil {
+			lastErr = fmt.Errorf("%s: %w", provider.Name(), err)
+			continue
+		}
+		return response, nil
+	}
+	return nil, fmt.Errorf("all providers failed, last error: %w", lastErr)
+}
@@ -0,0 +1,389 @@
+package paprikahelpers
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ============================================================================
+// PREDICATE/COMPARATOR QUERY DSL
+// ============================================================================
+
+// Comparator reports whether pool a should sort before pool b.
+type Comparator func(a, b Pool) bool
+
+// Predicate reports whether a pool matches a filter.
+type Predicate func(p Pool) bool
+
+// Field identifies a numeric Pool field usable with LessBy/comparisons in
+// the query parser.
+type Field string
+
+// Built-in numeric fields usable with LessBy and the query parser. Pool
+// carries no liquidity figure of its own (that lives on TokenSummary, per
+// token, not per pool), so there is deliberately no FieldLiquidityUSD here;
+// ParseQuery rejects "liquidity_usd" and any other unrecognized field
+// rather than silently sorting/filtering by a constant.
+const (
+	FieldVolumeUSD      Field = "volume_usd"
+	FieldPriceUSD       Field = "price_usd"
+	FieldTransactions   Field = "transactions"
+	FieldPriceChange24h Field = "last_price_change_usd_24h"
+	FieldPriceChange1h  Field = "last_price_change_usd_1h"
+	FieldPriceChange5m  Field = "last_price_change_usd_5m"
+	FieldCreatedAtBlock Field = "created_at_block_number"
+)
+
+// knownFields lists every Field fieldValue resolves to an actual Pool
+// value. parseOrderBy and parseCondition check against it so an
+// unrecognized field is a parse error instead of a silent no-op.
+var knownFields = map[Field]bool{
+	FieldVolumeUSD:      true,
+	FieldPriceUSD:       true,
+	FieldTransactions:   true,
+	FieldPriceChange24h: true,
+	FieldPriceChange1h:  true,
+	FieldPriceChange5m:  true,
+	FieldCreatedAtBlock: true,
+}
+
+// fieldValue extracts a Field's numeric value from a Pool. Only called
+// with fields already validated against knownFields.
+func fieldValue(p Pool, field Field) float64 {
+	switch field {
+	case FieldVolumeUSD:
+		return p.VolumeUSD
+	case FieldPriceUSD:
+		return p.PriceUSD
+	case FieldTransactions:
+		return float64(p.Transactions)
+	case FieldPriceChange24h:
+		return p.LastPriceChangeUSD24h
+	case FieldPriceChange1h:
+		return p.LastPriceChangeUSD1h
+	case FieldPriceChange5m:
+		return p.LastPriceChangeUSD5m
+	case FieldCreatedAtBlock:
+		return float64(p.CreatedAtBlockNumber)
+	default:
+		return 0
+	}
+}
+
+// LessBy returns a Comparator ordering pools by field, ascending or
+// descending.
+func LessBy(field Field, descending bool) Comparator {
+	return func(a, b Pool) bool {
+		va, vb := fieldValue(a, field), fieldValue(b, field)
+		if descending {
+			return va > vb
+		}
+		return va < vb
+	}
+}
+
+// Asc is a convenience for LessBy(field, false).
+func Asc(field Field) Comparator { return LessBy(field, false) }
+
+// Desc is a convenience for LessBy(field, true).
+func Desc(field Field) Comparator { return LessBy(field, true) }
+
+// Custom wraps an arbitrary comparator function, for callers whose ordering
+// doesn't fit a single Field.
+func Custom(fn func(a, b Pool) bool) Comparator { return Comparator(fn) }
+
+// TopNBy sorts pools by cmp and returns the first n, without mutating the
+// input slice.
+func TopNBy(pools []Pool, cmp Comparator, n int) []Pool {
+	sorted := make([]Pool, len(pools))
+	copy(sorted, pools)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return cmp(sorted[i], sorted[j])
+	})
+
+	if n < len(sorted) {
+		return sorted[:n]
+	}
+	return sorted
+}
+
+// Filter returns the pools matching every predicate in preds (logical AND
+// when more than one is given; use Or/And explicitly for other
+// combinations).
+func Filter(pools []Pool, preds ...Predicate) []Pool {
+	pred := And(preds...)
+	var filtered []Pool
+	for _, p := range pools {
+		if pred(p) {
+			filtered = append(filtered, p)
+		}
+	}
+	return filtered
+}
+
+// And combines predicates so all must match.
+func And(preds ...Predicate) Predicate {
+	return func(p Pool) bool {
+		for _, pred := range preds {
+			if !pred(p) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Or combines predicates so any may match.
+func Or(preds ...Predicate) Predicate {
+	return func(p Pool) bool {
+		for _, pred := range preds {
+			if pred(p) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not negates a predicate.
+func Not(pred Predicate) Predicate {
+	return func(p Pool) bool { return !pred(p) }
+}
+
+// MinVolume matches pools with volume_usd >= min.
+func MinVolume(min float64) Predicate {
+	return func(p Pool) bool { return p.VolumeUSD >= min }
+}
+
+// MaxVolume matches pools with volume_usd <= max.
+func MaxVolume(max float64) Predicate {
+	return func(p Pool) bool { return p.VolumeUSD <= max }
+}
+
+// OnDex matches pools on a DEX whose name contains dexName, case-insensitive
+// (mirroring FilterByDex).
+func OnDex(dexName string) Predicate {
+	return func(p Pool) bool {
+		return strings.Contains(strings.ToLower(p.DexName), strings.ToLower(dexName))
+	}
+}
+
+// TokenIn matches pools containing a token with the given address.
+func TokenIn(address string) Predicate {
+	return func(p Pool) bool {
+		for _, token := range p.Tokens {
+			if strings.EqualFold(token.ID, address) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// ============================================================================
+// MINI QUERY LANGUAGE
+//
+// Compiles queries of the form:
+//
+//	volume_usd>1000000 AND dex=uniswap ORDER BY volume_usd DESC LIMIT 10
+//
+// into a (Predicate, Comparator, limit) triple usable with Filter/TopNBy.
+// ============================================================================
+
+// CompiledQuery is the result of parsing a query string with ParseQuery.
+type CompiledQuery struct {
+	Predicate  Predicate
+	Comparator Comparator
+	Limit      int // 0 means unlimited
+}
+
+// Apply runs the compiled query against pools: filter, then sort, then
+// limit.
+func (q CompiledQuery) Apply(pools []Pool) []Pool {
+	filtered := pools
+	if q.Predicate != nil {
+		filtered = Filter(pools, q.Predicate)
+	}
+
+	if q.Comparator != nil {
+		filtered = TopNBy(filtered, q.Comparator, len(filtered))
+	}
+
+	if q.Limit > 0 && q.Limit < len(filtered) {
+		filtered = filtered[:q.Limit]
+	}
+
+	return filtered
+}
+
+// ParseQuery compiles a query string into a CompiledQuery. Supported
+// grammar:
+//
+//	<condition> (AND <condition>)*  [ORDER BY <field> [ASC|DESC]]  [LIMIT <n>]
+//	condition := <field><op><value>, op one of > < >= <= = !=
+func ParseQuery(query string) (CompiledQuery, error) {
+	var compiled CompiledQuery
+
+	remaining := query
+	orderIdx := indexKeyword(remaining, "ORDER BY")
+	limitIdx := indexKeyword(remaining, "LIMIT")
+
+	whereClause := remaining
+	var orderClause, limitClause string
+
+	cut := len(remaining)
+	if orderIdx != -1 && orderIdx < cut {
+		cut = orderIdx
+	}
+	if limitIdx != -1 && limitIdx < cut {
+		cut = limitIdx
+	}
+	whereClause = strings.TrimSpace(remaining[:cut])
+
+	if orderIdx != -1 {
+		end := len(remaining)
+		if limitIdx != -1 && limitIdx > orderIdx {
+			end = limitIdx
+		}
+		orderClause = strings.TrimSpace(remaining[orderIdx+len("ORDER BY") : end])
+	}
+
+	if limitIdx != -1 {
+		limitClause = strings.TrimSpace(remaining[limitIdx+len("LIMIT"):])
+	}
+
+	if whereClause != "" {
+		pred, err := parseConditions(whereClause)
+		if err != nil {
+			return compiled, err
+		}
+		compiled.Predicate = pred
+	}
+
+	if orderClause != "" {
+		cmp, err := parseOrderBy(orderClause)
+		if err != nil {
+			return compiled, err
+		}
+		compiled.Comparator = cmp
+	}
+
+	if limitClause != "" {
+		n, err := strconv.Atoi(limitClause)
+		if err != nil {
+			return compiled, fmt.Errorf("invalid LIMIT %q: %w", limitClause, err)
+		}
+		compiled.Limit = n
+	}
+
+	return compiled, nil
+}
+
+func indexKeyword(s, keyword string) int {
+	return strings.Index(strings.ToUpper(s), keyword)
+}
+
+func parseConditions(where string) (Predicate, error) {
+	parts := strings.Split(where, " AND ")
+	preds := make([]Predicate, 0, len(parts))
+
+	for _, part := range parts {
+		pred, err := parseCondition(strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		preds = append(preds, pred)
+	}
+
+	return And(preds...), nil
+}
+
+var queryOperators = []string{">=", "<=", "!=", ">", "<", "="}
+
+func parseCondition(cond string) (Predicate, error) {
+	for _, op := range queryOperators {
+		idx := strings.Index(cond, op)
+		if idx == -1 {
+			continue
+		}
+
+		field := Field(strings.TrimSpace(cond[:idx]))
+		value := strings.TrimSpace(cond[idx+len(op):])
+
+		if field == "dex" {
+			return parseDexCondition(op, value)
+		}
+
+		num, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q for field %q: %w", value, field, err)
+		}
+
+		return numericPredicate(field, op, num)
+	}
+
+	return nil, fmt.Errorf("unrecognized condition %q", cond)
+}
+
+func parseDexCondition(op, value string) (Predicate, error) {
+	switch op {
+	case "=":
+		return OnDex(value), nil
+	case "!=":
+		return Not(OnDex(value)), nil
+	default:
+		return nil, fmt.Errorf("dex only supports = and !=, got %q", op)
+	}
+}
+
+func numericPredicate(field Field, op string, value float64) (Predicate, error) {
+	if !knownFields[field] {
+		return nil, fmt.Errorf("unrecognized field %q", field)
+	}
+
+	switch op {
+	case ">":
+		return func(p Pool) bool { return fieldValue(p, field) > value }, nil
+	case ">=":
+		return func(p Pool) bool { return fieldValue(p, field) >= value }, nil
+	case "<":
+		return func(p Pool) bool { return fieldValue(p, field) < value }, nil
+	case "<=":
+		return func(p Pool) bool { return fieldValue(p, field) <= value }, nil
+	case "=":
+		return func(p Pool) bool { return fieldValue(p, field) == value }, nil
+	case "!=":
+		return func(p Pool) bool { return fieldValue(p, field) != value }, nil
+	default:
+		return nil, fmt.Errorf("unsupported operator %q", op)
+	}
+}
+
+func parseOrderBy(clause string) (Comparator, error) {
+	fields := strings.Fields(clause)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty ORDER BY clause")
+	}
+
+	field := Field(fields[0])
+	if !knownFields[field] {
+		return nil, fmt.Errorf("unrecognized sort field %q", field)
+	}
+
+	descending := false
+	if len(fields) > 1 {
+		switch strings.ToUpper(fields[1]) {
+		case "DESC":
+			descending = true
+		case "ASC":
+			descending = false
+		default:
+			return nil, fmt.Errorf("unrecognized sort direction %q", fields[1])
+		}
+	}
+
+	return LessBy(field, descending), nil
+}
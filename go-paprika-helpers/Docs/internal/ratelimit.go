@@ -0,0 +1,152 @@
+package paprikahelpers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// RATE LIMITING + RETRY FOR APIRequest
+// ============================================================================
+
+// requestLimiter is the package-level rate limiter consulted by
+// APIRequestCtx before every HTTP round trip. nil (the default) means no
+// rate limiting, matching APIRequest's original unlimited behavior.
+var (
+	requestLimiterMu sync.RWMutex
+	requestLimiter   *tokenBucket
+)
+
+// requestRetryPolicy governs how APIRequestCtx retries 429/5xx responses.
+// MaxAttempts of 1 (the default) means no retries, matching APIRequest's
+// original behavior until SetRetryPolicy opts in.
+var (
+	requestRetryMu     sync.RWMutex
+	requestRetryPolicy = RetryPolicy{MaxAttempts: 1, BaseDelay: 500 * time.Millisecond, MaxDelay: 10 * time.Second}
+)
+
+// SetRateLimit installs a token-bucket limiter shared by every call to
+// APIRequest/APIRequestCtx (and, transitively, every Get*/Get*Ctx helper
+// and BatchClient), capping steady-state throughput at qps requests per
+// second with bursts up to burst. Pass qps <= 0 to disable rate limiting.
+func SetRateLimit(qps float64, burst int) {
+	requestLimiterMu.Lock()
+	defer requestLimiterMu.Unlock()
+
+	if qps <= 0 {
+		requestLimiter = nil
+		return
+	}
+	if burst <= 0 {
+		burst = 1
+	}
+	requestLimiter = newTokenBucket(qps, burst)
+}
+
+// SetRetryPolicy configures how many times APIRequestCtx retries a
+// 429/5xx response and the exponential backoff (with jitter) between
+// attempts, honoring any Retry-After header the upstream sends. Pass
+// maxAttempts <= 1 to disable retries.
+func SetRetryPolicy(maxAttempts int, baseDelay, maxDelay time.Duration) {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	requestRetryMu.Lock()
+	defer requestRetryMu.Unlock()
+	requestRetryPolicy = RetryPolicy{MaxAttempts: maxAttempts, BaseDelay: baseDelay, MaxDelay: maxDelay}
+}
+
+func currentRequestLimiter() *tokenBucket {
+	requestLimiterMu.RLock()
+	defer requestLimiterMu.RUnlock()
+	return requestLimiter
+}
+
+func currentRequestRetryPolicy() RetryPolicy {
+	requestRetryMu.RLock()
+	defer requestRetryMu.RUnlock()
+	return requestRetryPolicy
+}
+
+// rateLimitedDo waits on the package-level rate limiter (if any), then
+// executes req, retrying on a 429/5xx response per the package-level
+// retry policy and honoring Retry-After. req must have no body (every
+// caller in this package issues GET requests), since a retried attempt
+// reuses the same *http.Request. It is context-aware: canceling ctx
+// aborts both the rate-limiter wait and any backoff sleep.
+func rateLimitedDo(ctx context.Context, req *http.Request) (*http.Response, error) {
+	limiter := currentRequestLimiter()
+	policy := currentRequestRetryPolicy()
+
+	delay := policy.BaseDelay
+	var lastErr error
+
+	for attempt := 0; attempt < policy.MaxAttempts; attempt++ {
+		if limiter != nil {
+			if err := limiter.Wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := httpClient.Do(req)
+		if err == nil && !isRetryableStatus(resp.StatusCode) {
+			return resp, nil
+		}
+
+		if err != nil {
+			lastErr = err
+		} else {
+			lastErr = fmt.Errorf("API request failed with retryable status %d", resp.StatusCode)
+		}
+
+		wait := delay
+		if err == nil {
+			if retryAfter, ok := retryAfterFromResponse(resp); ok {
+				wait = retryAfter
+				// Carry the hint in the error text too, so a Fetcher
+				// retrying this request (retryAfterFromError) honors it.
+				lastErr = fmt.Errorf("API request failed with retryable status %d retry-after=%d", resp.StatusCode, int(retryAfter/time.Second))
+			}
+			resp.Body.Close()
+		}
+
+		if attempt == policy.MaxAttempts-1 {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait + jitter(wait)):
+		}
+
+		delay *= 2
+		if delay > policy.MaxDelay {
+			delay = policy.MaxDelay
+		}
+	}
+
+	return nil, lastErr
+}
+
+// retryAfterFromResponse extracts a Retry-After duration from resp's
+// header, supporting the delay-seconds form (DexPaprika does not send the
+// HTTP-date form).
+func retryAfterFromResponse(resp *http.Response) (time.Duration, bool) {
+	header := resp.Header.Get("Retry-After")
+	if header == "" {
+		return 0, false
+	}
+
+	seconds, err := strconv.Atoi(header)
+	if err != nil {
+		return 0, false
+	}
+
+	return time.Duration(seconds) * time.Second, true
+}
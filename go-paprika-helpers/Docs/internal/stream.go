@@ -0,0 +1,473 @@
+package paprikahelpers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ============================================================================
+// STREAMING SUBSCRIPTIONS (live pool updates)
+// ============================================================================
+
+// PoolUpdate is a single delta emitted by Subscribe for a watched pool.
+type PoolUpdate struct {
+	Network        string    `json:"network"`
+	PoolID         string    `json:"pool_id"`
+	PriceUSD       float64   `json:"price_usd"`
+	PriceChangeUSD float64   `json:"price_change_usd"`
+	VolumeUSD      float64   `json:"volume_usd"`
+	Sequence       int64     `json:"sequence"`
+	Timestamp      time.Time `json:"timestamp"`
+}
+
+// SubscriptionFilter narrows which updates a subscriber receives.
+type SubscriptionFilter struct {
+	// MinVolumeUSD drops updates below this volume. Zero means no filter.
+	MinVolumeUSD float64
+	// MinPriceChangePercent drops updates whose absolute price move since
+	// the last delivered update is smaller than this. Zero means no filter.
+	MinPriceChangePercent float64
+}
+
+func (f SubscriptionFilter) allows(prev, next PoolUpdate, hasPrev bool) bool {
+	if next.VolumeUSD < f.MinVolumeUSD {
+		return false
+	}
+	if f.MinPriceChangePercent > 0 && hasPrev {
+		change := math.Abs(CalculatePriceChange(next.PriceUSD, prev.PriceUSD))
+		if change < f.MinPriceChangePercent {
+			return false
+		}
+	}
+	return true
+}
+
+// subscription tracks one caller's channel, filter, and resume cursor.
+type subscription struct {
+	ch       chan<- PoolUpdate
+	filter   SubscriptionFilter
+	lastSent map[string]PoolUpdate
+}
+
+// streamState is the shared, reconnect-safe state for a single (network,
+// poolID) room: the last known pool snapshot and the fan-out list of
+// subscribers sharing it. cancel stops the room's poller once the last
+// subscriber leaves; it is independent of any single subscriber's ctx.
+type streamState struct {
+	mu       sync.Mutex
+	lastPool *Pool
+	cursor   int64
+	subs     map[int]*subscription
+	nextSub  int
+	cancel   context.CancelFunc
+}
+
+var (
+	streamsMu sync.Mutex
+	streams   = make(map[string]*streamState)
+)
+
+func streamKey(network, poolID string) string {
+	return network + ":" + poolID
+}
+
+// Subscribe opens (or joins, if already open) a polling-backed live feed for
+// the given network/poolIDs and streams PoolUpdate deltas into ch until ctx
+// is canceled. Many callers subscribing to the same pool share one upstream
+// polling loop, so adding subscribers doesn't multiply API calls.
+//
+// DexPaprika has no push transport today, so this is implemented as an
+// adaptive-backoff poller that diffs successive GetPoolDetails snapshots;
+// the public surface (Subscribe/PoolUpdate) is stable if a push transport
+// is added later.
+func Subscribe(ctx context.Context, network string, poolIDs []string, ch chan<- PoolUpdate, filter SubscriptionFilter) error {
+	if ch == nil {
+		return fmt.Errorf("subscribe: nil channel")
+	}
+
+	for _, poolID := range poolIDs {
+		if err := joinRoom(ctx, network, poolID, ch, filter); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func joinRoom(ctx context.Context, network, poolID string, ch chan<- PoolUpdate, filter SubscriptionFilter) error {
+	key := streamKey(network, poolID)
+
+	streamsMu.Lock()
+	state, exists := streams[key]
+	var roomCtx context.Context
+	if !exists {
+		var cancel context.CancelFunc
+		roomCtx, cancel = context.WithCancel(context.Background())
+		state = &streamState{subs: make(map[int]*subscription), cancel: cancel}
+		streams[key] = state
+	}
+
+	state.mu.Lock()
+	id := state.nextSub
+	state.nextSub++
+	state.subs[id] = &subscription{ch: ch, filter: filter, lastSent: make(map[string]PoolUpdate)}
+	state.mu.Unlock()
+	streamsMu.Unlock()
+
+	if !exists {
+		go pollRoom(roomCtx, network, poolID, state)
+	}
+
+	go func() {
+		<-ctx.Done()
+		leaveRoom(key, state, id)
+	}()
+
+	return nil
+}
+
+// leaveRoom drops subscriber id from state and, if that was the room's last
+// subscriber, tears the room down — canceling its poller and removing it
+// from streams so a future Subscribe call starts a fresh one. Every
+// subscriber's departure runs this, not just the one that happened to
+// create the room, so the room's lifetime is ref-counted rather than tied
+// to whichever ctx was first.
+func leaveRoom(key string, state *streamState, id int) {
+	streamsMu.Lock()
+	defer streamsMu.Unlock()
+
+	state.mu.Lock()
+	delete(state.subs, id)
+	empty := len(state.subs) == 0
+	state.mu.Unlock()
+
+	if empty && streams[key] == state {
+		delete(streams, key)
+		state.cancel()
+	}
+}
+
+// pollRoom drives one upstream polling loop per (network, poolID), with
+// exponential backoff plus jitter on errors and a reset to the base interval
+// on success. ctx is the room's own context (see joinRoom/leaveRoom), not
+// any single subscriber's, so the poller keeps running for every other
+// subscriber sharing the room until the last one leaves.
+func pollRoom(ctx context.Context, network, poolID string, state *streamState) {
+	const (
+		baseInterval = 5 * time.Second
+		maxInterval  = 2 * time.Minute
+	)
+
+	interval := baseInterval
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+
+		pool, err := GetPoolDetails(network, poolID, false)
+		if err != nil {
+			interval = nextBackoff(interval, maxInterval)
+			continue
+		}
+		interval = baseInterval
+
+		state.mu.Lock()
+		state.cursor++
+		update := PoolUpdate{
+			Network:        network,
+			PoolID:         poolID,
+			PriceUSD:       pool.PriceUSD,
+			PriceChangeUSD: pool.LastPriceChangeUSD24h,
+			VolumeUSD:      pool.VolumeUSD,
+			Sequence:       state.cursor,
+			Timestamp:      time.Now(),
+		}
+		state.lastPool = pool
+
+		for _, sub := range state.subs {
+			prev, hasPrev := sub.lastSent[poolID]
+			if !sub.filter.allows(prev, update, hasPrev) {
+				continue
+			}
+			sub.lastSent[poolID] = update
+			select {
+			case sub.ch <- update:
+			default:
+				// Slow consumer: drop rather than block the shared poller.
+			}
+		}
+		state.mu.Unlock()
+	}
+}
+
+func nextBackoff(current, max time.Duration) time.Duration {
+	next := current * 2
+	if next > max {
+		next = max
+	}
+	jitter := time.Duration(rand.Int63n(int64(next)/4 + 1))
+	return next + jitter
+}
+
+// ============================================================================
+// MULTIPLEXED STREAM (pool/tx/ohlcv rooms over one logical connection)
+// ============================================================================
+
+// StreamMetrics is a point-in-time snapshot of a Stream's activity, exposed
+// so callers can wire it into their own metrics system (Prometheus, etc.).
+type StreamMetrics struct {
+	ConnectedSubscriptions int
+	EventsEmitted          int64
+}
+
+// Stream multiplexes many topic subscriptions ("rooms") over a single
+// logical connection. DexPaprika has no native socket endpoint, so each
+// room is a polling-backed adapter that diffs successive snapshots, but the
+// topic/Subscribe surface matches what a real push transport would expose.
+//
+// Supported topics:
+//
+//	pool:<network>:<address>        -> PoolUpdate events
+//	tx:<network>:<poolAddress>       -> Transaction events (new txs only)
+//	ohlcv:<network>:<poolAddress>:<timeframe> -> OHLCVRecord events (new candles only)
+type Stream struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu    sync.Mutex
+	rooms map[string]*topicRoom
+
+	eventsEmitted atomic.Int64
+}
+
+// NewStream creates a Stream bound to ctx; all rooms and subscriptions are
+// torn down when ctx is canceled or Close is called.
+func NewStream(ctx context.Context) *Stream {
+	ctx, cancel := context.WithCancel(ctx)
+	return &Stream{ctx: ctx, cancel: cancel, rooms: make(map[string]*topicRoom)}
+}
+
+// topicRoom polls one topic and fans its events out to every subscriber.
+type topicRoom struct {
+	mu      sync.Mutex
+	subs    map[int]chan<- interface{}
+	nextSub int
+	seen    map[string]bool // dedupe key -> seen, for tx/ohlcv rooms
+}
+
+// Subscribe joins topic, starting its poller if this is the first
+// subscriber, and returns a channel of events (PoolUpdate, Transaction, or
+// OHLCVRecord depending on the topic prefix).
+func (s *Stream) Subscribe(topic string) (<-chan interface{}, error) {
+	parts := strings.Split(topic, ":")
+	if len(parts) < 3 {
+		return nil, fmt.Errorf("stream: malformed topic %q", topic)
+	}
+
+	s.mu.Lock()
+	room, exists := s.rooms[topic]
+	if !exists {
+		room = &topicRoom{subs: make(map[int]chan<- interface{}), seen: make(map[string]bool)}
+		s.rooms[topic] = room
+	}
+	s.mu.Unlock()
+
+	ch := make(chan interface{}, 16)
+	room.mu.Lock()
+	id := room.nextSub
+	room.nextSub++
+	room.subs[id] = ch
+	shouldStart := !exists
+	room.mu.Unlock()
+
+	if shouldStart {
+		go s.pollTopic(topic, parts, room)
+	}
+
+	go func() {
+		<-s.ctx.Done()
+		room.mu.Lock()
+		delete(room.subs, id)
+		room.mu.Unlock()
+		close(ch)
+	}()
+
+	return ch, nil
+}
+
+// Metrics returns a snapshot of current subscription and event counts.
+func (s *Stream) Metrics() StreamMetrics {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	connected := 0
+	for _, room := range s.rooms {
+		room.mu.Lock()
+		connected += len(room.subs)
+		room.mu.Unlock()
+	}
+
+	return StreamMetrics{
+		ConnectedSubscriptions: connected,
+		EventsEmitted:          s.eventsEmitted.Load(),
+	}
+}
+
+// Close tears down every room and subscription on this Stream.
+func (s *Stream) Close() {
+	s.cancel()
+}
+
+func (s *Stream) emit(room *topicRoom, event interface{}) {
+	room.mu.Lock()
+	defer room.mu.Unlock()
+
+	for _, ch := range room.subs {
+		select {
+		case ch <- event:
+			s.eventsEmitted.Add(1)
+		default:
+			// Slow consumer: drop rather than block the room's poller.
+		}
+	}
+}
+
+func (s *Stream) pollTopic(topic string, parts []string, room *topicRoom) {
+	const interval = 5 * time.Second
+
+	switch parts[0] {
+	case "pool":
+		s.pollPoolTopic(topic, parts, room, interval)
+	case "tx":
+		s.pollTxTopic(topic, parts, room, interval)
+	case "ohlcv":
+		s.pollOHLCVTopic(topic, parts, room, interval)
+	}
+}
+
+func (s *Stream) pollPoolTopic(topic string, parts []string, room *topicRoom, interval time.Duration) {
+	if len(parts) < 3 {
+		return
+	}
+	network, address := parts[1], parts[2]
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			s.dropRoom(topic)
+			return
+		case <-ticker.C:
+		}
+
+		pool, err := GetPoolDetails(network, address, false)
+		if err != nil {
+			continue
+		}
+
+		s.emit(room, PoolUpdate{
+			Network:        network,
+			PoolID:         address,
+			PriceUSD:       pool.PriceUSD,
+			PriceChangeUSD: pool.LastPriceChangeUSD24h,
+			VolumeUSD:      pool.VolumeUSD,
+			Timestamp:      time.Now(),
+		})
+	}
+}
+
+func (s *Stream) pollTxTopic(topic string, parts []string, room *topicRoom, interval time.Duration) {
+	if len(parts) < 3 {
+		return
+	}
+	network, address := parts[1], parts[2]
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			s.dropRoom(topic)
+			return
+		case <-ticker.C:
+		}
+
+		resp, err := GetPoolTransactions(network, address, map[string]string{"limit": "20"})
+		if err != nil {
+			continue
+		}
+
+		room.mu.Lock()
+		var fresh []Transaction
+		for _, tx := range resp.Transactions {
+			if !room.seen[tx.ID] {
+				room.seen[tx.ID] = true
+				fresh = append(fresh, tx)
+			}
+		}
+		room.mu.Unlock()
+
+		for _, tx := range fresh {
+			s.emit(room, tx)
+		}
+	}
+}
+
+func (s *Stream) pollOHLCVTopic(topic string, parts []string, room *topicRoom, interval time.Duration) {
+	if len(parts) < 4 {
+		return
+	}
+	network, address, timeframe := parts[1], parts[2], parts[3]
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	start := time.Now().Add(-1 * time.Hour).Format(time.RFC3339)
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			s.dropRoom(topic)
+			return
+		case <-ticker.C:
+		}
+
+		records, err := GetPoolOHLCV(network, address, start, map[string]string{"interval": timeframe})
+		if err != nil {
+			continue
+		}
+
+		room.mu.Lock()
+		var fresh []OHLCVRecord
+		for _, rec := range records {
+			key := rec.TimeOpen
+			if !room.seen[key] {
+				room.seen[key] = true
+				fresh = append(fresh, rec)
+			}
+		}
+		room.mu.Unlock()
+
+		for _, rec := range fresh {
+			s.emit(room, rec)
+		}
+	}
+}
+
+func (s *Stream) dropRoom(topic string) {
+	s.mu.Lock()
+	delete(s.rooms, topic)
+	s.mu.Unlock()
+}
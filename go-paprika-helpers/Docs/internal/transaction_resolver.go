@@ -0,0 +1,261 @@
+package paprikahelpers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ============================================================================
+// ON-CHAIN TRANSACTION SWEEP / CROSS-SOURCE VERIFICATION
+// ============================================================================
+
+// OnChainLog is a single decoded event log entry from a transaction receipt.
+type OnChainLog struct {
+	Address string   `json:"address"`
+	Topics  []string `json:"topics"`
+	Data    string   `json:"data"`
+}
+
+// OnChainReceipt is the normalized on-chain transaction receipt fetched
+// from one external source (an Etherscan/Blockscout-compatible API or a
+// raw JSON-RPC endpoint).
+type OnChainReceipt struct {
+	Source      string       `json:"source"`
+	BlockNumber int64        `json:"block_number"`
+	Status      string       `json:"status"`
+	Logs        []OnChainLog `json:"logs"`
+}
+
+// ResolvedTransaction merges a Paprika Transaction with the on-chain
+// receipt fetched from an external source. SwapEvents are decoded
+// best-effort from the receipt's logs and aren't decimal-adjusted
+// (Transaction doesn't carry token decimals), so Discrepancies only
+// flags structural mismatches this package can verify with confidence:
+// block number and revert status.
+type ResolvedTransaction struct {
+	Transaction   Transaction     `json:"transaction"`
+	Receipt       *OnChainReceipt `json:"receipt,omitempty"`
+	SwapEvents    []SwapEvent     `json:"swap_events,omitempty"`
+	Discrepancies []string        `json:"discrepancies,omitempty"`
+	Err           string          `json:"error,omitempty"`
+}
+
+// TransactionResolver cross-checks Paprika transactions against external
+// block explorers/RPC endpoints, the same "alternate endpoint list" idea
+// as P2Pool's "other" transaction-lookup config.
+type TransactionResolver struct {
+	// Sources is tried in order; the first to answer successfully wins.
+	// Each entry is a base URL for a JSON-RPC endpoint — Etherscan and
+	// Blockscout both also expose an RPC-compatible proxy, so the same
+	// eth_getTransactionReceipt call works against all three.
+	Sources []string
+}
+
+// NewTransactionResolver builds a TransactionResolver trying sources in
+// order.
+func NewTransactionResolver(sources ...string) *TransactionResolver {
+	return &TransactionResolver{Sources: sources}
+}
+
+// Resolve fetches tx's on-chain receipt from the first reachable source in
+// r.Sources, decodes any swap-like logs, and reports discrepancies between
+// Paprika's reported data and what's on-chain.
+func (r *TransactionResolver) Resolve(ctx context.Context, tx Transaction) ResolvedTransaction {
+	resolved := ResolvedTransaction{Transaction: tx}
+
+	var lastErr error
+	for _, source := range r.Sources {
+		receipt, err := fetchReceipt(ctx, source, tx.ID)
+		if err != nil {
+			lastErr = fmt.Errorf("%s: %w", source, err)
+			continue
+		}
+
+		resolved.Receipt = receipt
+		resolved.SwapEvents = decodeSwapEvents(tx, receipt)
+		resolved.Discrepancies = diffTransaction(tx, receipt)
+		return resolved
+	}
+
+	if lastErr != nil {
+		resolved.Err = fmt.Sprintf("all sources failed, last error: %s", lastErr)
+	}
+	return resolved
+}
+
+// transactionResolveConcurrency bounds how many Resolve calls VerifyTransactions
+// runs at once.
+const transactionResolveConcurrency = 8
+
+// VerifyTransactions cross-checks a batch of Paprika transactions against
+// sources concurrently (the same bounded sem+WaitGroup shape
+// BatchClient.FetchPools uses), returning one ResolvedTransaction per
+// input in the same order regardless of which finishes first.
+func VerifyTransactions(ctx context.Context, txs []Transaction, sources []string) []ResolvedTransaction {
+	resolver := NewTransactionResolver(sources...)
+	results := make([]ResolvedTransaction, len(txs))
+
+	sem := make(chan struct{}, transactionResolveConcurrency)
+	var wg sync.WaitGroup
+
+	for i, tx := range txs {
+		i, tx := i, tx
+
+		select {
+		case <-ctx.Done():
+			results[i] = ResolvedTransaction{Transaction: tx, Err: ctx.Err().Error()}
+			continue
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = resolver.Resolve(ctx, tx)
+		}()
+	}
+
+	wg.Wait()
+	return results
+}
+
+// jsonRPCRequest is a minimal JSON-RPC 2.0 envelope for eth_getTransactionReceipt.
+type jsonRPCRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+	ID      int           `json:"id"`
+}
+
+type jsonRPCReceiptResult struct {
+	BlockNumber string `json:"blockNumber"`
+	Status      string `json:"status"`
+	Logs        []struct {
+		Address string   `json:"address"`
+		Topics  []string `json:"topics"`
+		Data    string   `json:"data"`
+	} `json:"logs"`
+}
+
+// fetchReceipt calls eth_getTransactionReceipt against source for txID and
+// normalizes the result into an OnChainReceipt.
+func fetchReceipt(ctx context.Context, source, txID string) (*OnChainReceipt, error) {
+	body, err := json.Marshal(jsonRPCRequest{
+		JSONRPC: "2.0",
+		Method:  "eth_getTransactionReceipt",
+		Params:  []interface{}{txID},
+		ID:      1,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, source, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+
+	var payload struct {
+		Result *jsonRPCReceiptResult `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("failed to parse receipt response: %w", err)
+	}
+	if payload.Error != nil {
+		return nil, fmt.Errorf("rpc error: %s", payload.Error.Message)
+	}
+	if payload.Result == nil {
+		return nil, fmt.Errorf("transaction not found")
+	}
+
+	blockNumber, _ := strconv.ParseInt(strings.TrimPrefix(payload.Result.BlockNumber, "0x"), 16, 64)
+
+	logs := make([]OnChainLog, 0, len(payload.Result.Logs))
+	for _, l := range payload.Result.Logs {
+		logs = append(logs, OnChainLog{Address: l.Address, Topics: l.Topics, Data: l.Data})
+	}
+
+	return &OnChainReceipt{
+		Source:      source,
+		BlockNumber: blockNumber,
+		Status:      payload.Result.Status,
+		Logs:        logs,
+	}, nil
+}
+
+// decodeSwapEvents best-effort-decodes Uniswap-V2-style Swap logs (4
+// packed uint256 words: amount0In, amount1In, amount0Out, amount1Out) from
+// receipt's logs, labeling the traded tokens from tx's own Token0Symbol/
+// Token1Symbol. Amounts are the raw on-chain integers, not decimal-adjusted,
+// since Transaction doesn't carry token decimals.
+func decodeSwapEvents(tx Transaction, receipt *OnChainReceipt) []SwapEvent {
+	var events []SwapEvent
+
+	for _, l := range receipt.Logs {
+		data := strings.TrimPrefix(l.Data, "0x")
+		if len(data) != 256 {
+			continue
+		}
+
+		words := make([]float64, 4)
+		for i := 0; i < 4; i++ {
+			word := new(big.Int)
+			if _, ok := word.SetString(data[i*64:(i+1)*64], 16); !ok {
+				continue
+			}
+			f := new(big.Float).SetInt(word)
+			words[i], _ = f.Float64()
+		}
+		amount0In, amount1In, amount0Out, amount1Out := words[0], words[1], words[2], words[3]
+
+		event := SwapEvent{PoolID: tx.PoolID}
+		if amount0In > 0 {
+			event.TokenIn, event.AmountIn = tx.Token0Symbol, amount0In
+			event.TokenOut, event.AmountOut = tx.Token1Symbol, amount1Out
+		} else {
+			event.TokenIn, event.AmountIn = tx.Token1Symbol, amount1In
+			event.TokenOut, event.AmountOut = tx.Token0Symbol, amount0Out
+		}
+		events = append(events, event)
+	}
+
+	return events
+}
+
+// diffTransaction compares tx against receipt and reports the fields they
+// disagree on.
+func diffTransaction(tx Transaction, receipt *OnChainReceipt) []string {
+	var diffs []string
+
+	if receipt.BlockNumber != 0 && receipt.BlockNumber != int64(tx.CreatedAtBlockNumber) {
+		diffs = append(diffs, fmt.Sprintf("block_number: paprika=%d on-chain=%d", int64(tx.CreatedAtBlockNumber), receipt.BlockNumber))
+	}
+
+	if receipt.Status != "" && receipt.Status != "0x1" {
+		diffs = append(diffs, fmt.Sprintf("status: on-chain transaction reverted (status=%s)", receipt.Status))
+	}
+
+	return diffs
+}
@@ -161,9 +161,44 @@ type TokenPoolsResponse struct {
 
 // SearchResponse represents the response from search endpoint
 type SearchResponse struct {
-	Tokens []Token `json:"tokens"`
-	Pools  []Pool  `json:"pools"`
-	Dexes  []Dex   `json:"dexes"`
+	Tokens      []Token         `json:"tokens"`
+	Pools       []Pool          `json:"pools"`
+	Dexes       []Dex           `json:"dexes"`
+	Collections []NFTCollection `json:"collections,omitempty"`
+}
+
+// NFTCollection represents an NFT collection
+type NFTCollection struct {
+	ID            string  `json:"id"`
+	Chain         string  `json:"chain"`
+	Contract      string  `json:"contract"`
+	Name          string  `json:"name"`
+	Symbol        string  `json:"symbol"`
+	FloorPriceUSD float64 `json:"floor_price_usd"`
+	TotalSupply   int64   `json:"total_supply"`
+	Owners        int64   `json:"owners"`
+	Volume24h     float64 `json:"volume_24h"`
+}
+
+// NFTCollectionsResponse represents the response from NFT collection endpoints
+type NFTCollectionsResponse struct {
+	Collections []NFTCollection `json:"collections"`
+	PageInfo    PageInfo        `json:"page_info"`
+}
+
+// NFTAsset represents a single NFT within a collection
+type NFTAsset struct {
+	TokenID     string            `json:"token_id"`
+	Owner       string            `json:"owner"`
+	ImageURL    string            `json:"image_url"`
+	Traits      map[string]string `json:"traits,omitempty"`
+	LastSaleUSD float64           `json:"last_sale_usd"`
+}
+
+// NFTAssetsResponse represents the response from NFT asset endpoints
+type NFTAssetsResponse struct {
+	Assets   []NFTAsset `json:"assets"`
+	PageInfo PageInfo   `json:"page_info"`
 }
 
 // SystemStats represents system statistics
@@ -184,6 +219,9 @@ type APIError struct {
 type CacheEntry struct {
 	Data      interface{}
 	Timestamp time.Time
+	// TTL is the lifetime this entry was stored with. <= 0 means "use
+	// whatever default the Cache implementation falls back to".
+	TTL time.Duration
 }
 
 // AnalysisResult represents the result of various analysis functions
@@ -219,12 +257,36 @@ type DexDistribution struct {
 	Distribution  map[string]float64 `json:"distribution"`
 	TopDexes      []string           `json:"top_dexes"`
 	Concentration float64            `json:"concentration"`
+
+	// ConcentrationClass labels Concentration using the standard
+	// antitrust HHI breakpoints (on the 0..1 scale): "Unconcentrated"
+	// below 0.15, "Moderate" from 0.15 to 0.25, "Highly Concentrated"
+	// above 0.25.
+	ConcentrationClass string `json:"concentration_class"`
+	// EffectiveN is 1/Concentration, the "numbers-equivalent" of equally
+	// sized competitors that would produce the same HHI.
+	EffectiveN float64 `json:"effective_n"`
+	// CR4 and CR8 are the combined volume share of the top 4 and top 8
+	// DEXes by volume (0 if fewer DEXes are present than the ratio asks for).
+	CR4 float64 `json:"cr4"`
+	CR8 float64 `json:"cr8"`
+	// RosenbluthIndex is an alternative concentration measure,
+	// 1 / (2*Σ(i·pᵢ) - 1), where pᵢ are volume shares ranked descending.
+	RosenbluthIndex float64 `json:"rosenbluth_index"`
+}
+
+// DexDistributionSnapshot is one DexDistribution measured over a single
+// time bucket, returned by AnalyzeDexDistributionOverTime.
+type DexDistributionSnapshot struct {
+	BucketTime      time.Time       `json:"bucket_time"`
+	DexDistribution DexDistribution `json:"dex_distribution"`
 }
 
 // MarketOverview represents overall market statistics
 type MarketOverview struct {
 	SystemStats     SystemStats            `json:"system_stats"`
 	NetworkOverview map[string]interface{} `json:"network_overview"`
+	NFTCollections  []NFTCollection        `json:"nft_collections,omitempty"`
 	Timestamp       string                 `json:"timestamp"`
 }
 
@@ -0,0 +1,194 @@
+package paprikahelpers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// ============================================================================
+// WORKER POOL (bounded concurrency for the Async* helpers)
+// ============================================================================
+
+// Future is a handle to a task submitted to a WorkerPool. Wait blocks until
+// the task finishes or ctx is canceled, whichever comes first.
+type Future struct {
+	done  chan struct{}
+	value interface{}
+	err   error
+}
+
+// Wait blocks until the task completes, returning its result, or returns
+// ctx.Err() if ctx is canceled first (the task itself keeps running to
+// completion in that case; it isn't killed, just no longer waited on).
+func (f *Future) Wait(ctx context.Context) (interface{}, error) {
+	select {
+	case <-f.done:
+		return f.value, f.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// WorkerPool bounds the number of tasks running at once (unlike the
+// original Async* helpers, which spawned one goroutine per input and could
+// fan out thousands of concurrent requests), optionally pacing task starts
+// through a rate.Limiter and retrying failed tasks with exponential backoff
+// and jitter. A single ctx passed to every Submit/Map call in a batch means
+// canceling it cancels every in-flight and not-yet-started task in that
+// batch at once.
+type WorkerPool struct {
+	// MaxConcurrency caps the number of tasks running at once.
+	MaxConcurrency int
+	// Limiter paces task starts, independent of MaxConcurrency. Nil means
+	// unpaced (only MaxConcurrency applies).
+	Limiter *rate.Limiter
+	// Retry governs how many times a failed task is retried and the
+	// backoff between attempts. MaxAttempts of 1 means no retries.
+	Retry RetryPolicy
+
+	sem chan struct{}
+}
+
+// NewWorkerPool builds a WorkerPool with the given concurrency cap, rate
+// limiter (nil to disable pacing), and retry policy. maxConcurrency <= 0
+// defaults to 8.
+func NewWorkerPool(maxConcurrency int, limiter *rate.Limiter, retry RetryPolicy) *WorkerPool {
+	if maxConcurrency <= 0 {
+		maxConcurrency = 8
+	}
+	if retry.MaxAttempts < 1 {
+		retry.MaxAttempts = 1
+	}
+	return &WorkerPool{
+		MaxConcurrency: maxConcurrency,
+		Limiter:        limiter,
+		Retry:          retry,
+		sem:            make(chan struct{}, maxConcurrency),
+	}
+}
+
+// Submit queues fn to run as soon as a worker slot is free, pacing its
+// start against Limiter and retrying it per Retry on failure. It returns
+// immediately with a Future; it only blocks if every worker slot is
+// currently in use, or if ctx is canceled while waiting for one.
+func (p *WorkerPool) Submit(ctx context.Context, fn func(ctx context.Context) (interface{}, error)) *Future {
+	f := &Future{done: make(chan struct{})}
+
+	select {
+	case p.sem <- struct{}{}:
+	case <-ctx.Done():
+		f.err = ctx.Err()
+		close(f.done)
+		return f
+	}
+
+	go func() {
+		defer func() { <-p.sem }()
+		defer close(f.done)
+		f.value, f.err = p.runWithRetry(ctx, fn)
+	}()
+
+	return f
+}
+
+// Map runs fn once per entry in inputs through the pool, then waits for
+// every result and returns one AsyncResult per input, in the same order as
+// inputs regardless of which task finished first.
+func (p *WorkerPool) Map(ctx context.Context, inputs []string, fn func(ctx context.Context, input string) (interface{}, error)) []AsyncResult {
+	futures := make([]*Future, len(inputs))
+	for i, input := range inputs {
+		in := input
+		futures[i] = p.Submit(ctx, func(taskCtx context.Context) (interface{}, error) {
+			return fn(taskCtx, in)
+		})
+	}
+
+	results := make([]AsyncResult, len(inputs))
+	for i, future := range futures {
+		value, err := future.Wait(ctx)
+		if err != nil {
+			results[i] = AsyncResult{Error: err.Error()}
+		} else {
+			results[i] = AsyncResult{Data: value}
+		}
+	}
+	return results
+}
+
+// runWithRetry runs fn, retrying on error per p.Retry with exponential
+// backoff and jitter. The 429/5xx-aware retry-after handling for the
+// actual HTTP round trip already happens a layer down in rateLimitedDo
+// (every Get*Ctx helper goes through APIRequestCtx, which calls it); this
+// retry exists for task-level failures more broadly (e.g. a canceled
+// limiter wait, or a caller-supplied fn that isn't HTTP at all).
+func (p *WorkerPool) runWithRetry(ctx context.Context, fn func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	delay := p.Retry.BaseDelay
+	var lastErr error
+
+	for attempt := 0; attempt < p.Retry.MaxAttempts; attempt++ {
+		if p.Limiter != nil {
+			if err := p.Limiter.Wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		value, err := fn(ctx)
+		if err == nil {
+			return value, nil
+		}
+		lastErr = err
+
+		if attempt == p.Retry.MaxAttempts-1 {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay + jitter(delay)):
+		}
+
+		delay *= 2
+		if delay > p.Retry.MaxDelay {
+			delay = p.Retry.MaxDelay
+		}
+	}
+
+	return nil, lastErr
+}
+
+// ============================================================================
+// DEFAULT ASYNC WORKER POOL
+// ============================================================================
+
+// asyncPool is the WorkerPool used by AsyncGetMultiplePools,
+// AsyncGetTokenDataBatch, and AsyncBatchSearch. Its defaults (8 workers,
+// 10 requests/sec with a burst of 20, 3 attempts) are deliberately modest
+// so a batch of thousands of inputs can't trip the API's rate limits or
+// fan out unbounded goroutines.
+var (
+	asyncPoolMu sync.RWMutex
+	asyncPool   = NewWorkerPool(8, rate.NewLimiter(rate.Limit(10), 20), RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   500 * time.Millisecond,
+		MaxDelay:    5 * time.Second,
+	})
+)
+
+// SetAsyncWorkerPool overrides the WorkerPool backing AsyncGetMultiplePools,
+// AsyncGetTokenDataBatch, and AsyncBatchSearch, so callers can tune
+// concurrency, pacing, or retry behavior for bulk async operations.
+func SetAsyncWorkerPool(pool *WorkerPool) {
+	asyncPoolMu.Lock()
+	defer asyncPoolMu.Unlock()
+	asyncPool = pool
+}
+
+func currentAsyncWorkerPool() *WorkerPool {
+	asyncPoolMu.RLock()
+	defer asyncPoolMu.RUnlock()
+	return asyncPool
+}
@@ -0,0 +1,236 @@
+// Package paprikaexporter implements a prometheus.Collector that serves
+// pool, token, and system metrics sourced from paprikahelpers.
+package paprikaexporter
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	paprikahelpers "github.com/donbagger/code-jam/go-paprika-helpers/Docs/internal"
+)
+
+// PoolTarget is one pool the exporter watches.
+type PoolTarget struct {
+	Network string `yaml:"network"`
+	Address string `yaml:"address"`
+}
+
+// TokenTarget is one token the exporter watches.
+type TokenTarget struct {
+	Network string `yaml:"network"`
+	Address string `yaml:"address"`
+}
+
+// Config is the exporter's watch-list, typically loaded from YAML.
+type Config struct {
+	Pools  []PoolTarget  `yaml:"pools"`
+	Tokens []TokenTarget `yaml:"tokens"`
+
+	// MinScrapeInterval bounds how often a given target is actually
+	// refreshed, independent of how often Prometheus scrapes /metrics.
+	MinScrapeInterval time.Duration `yaml:"min_scrape_interval"`
+}
+
+var (
+	poolVolumeUSD = prometheus.NewDesc(
+		"dexpaprika_pool_volume_usd",
+		"Pool trading volume in USD",
+		[]string{"network", "dex", "pool", "symbol"}, nil,
+	)
+	poolPriceUSD = prometheus.NewDesc(
+		"dexpaprika_pool_price_usd",
+		"Pool price in USD",
+		[]string{"network", "dex", "pool", "symbol"}, nil,
+	)
+	poolPriceChange24h = prometheus.NewDesc(
+		"dexpaprika_pool_price_change_24h",
+		"Pool price change over the last 24h",
+		[]string{"network", "dex", "pool", "symbol"}, nil,
+	)
+	tokenLiquidityUSD = prometheus.NewDesc(
+		"dexpaprika_token_liquidity_usd",
+		"Token liquidity in USD across pools",
+		[]string{"network", "token", "symbol"}, nil,
+	)
+	systemChains = prometheus.NewDesc(
+		"dexpaprika_system_chains", "Number of chains tracked by DexPaprika", nil, nil,
+	)
+	systemPools = prometheus.NewDesc(
+		"dexpaprika_system_pools", "Number of pools tracked by DexPaprika", nil, nil,
+	)
+	systemTokens = prometheus.NewDesc(
+		"dexpaprika_system_tokens", "Number of tokens tracked by DexPaprika", nil, nil,
+	)
+	systemFactories = prometheus.NewDesc(
+		"dexpaprika_system_factories", "Number of factories tracked by DexPaprika", nil, nil,
+	)
+)
+
+// targetState caches the last scrape for one target, enforcing
+// Config.MinScrapeInterval independent of Prometheus's own scrape period.
+type targetState struct {
+	mu         sync.Mutex
+	lastUpdate time.Time
+	pool       *paprikahelpers.Pool
+	token      *paprikahelpers.Token
+}
+
+func (t *targetState) stale(minInterval time.Duration) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return time.Since(t.lastUpdate) >= minInterval
+}
+
+// Exporter implements prometheus.Collector over a Config's watch-list.
+type Exporter struct {
+	cfg Config
+
+	poolStates  map[PoolTarget]*targetState
+	tokenStates map[TokenTarget]*targetState
+}
+
+// New builds an Exporter for cfg, defaulting MinScrapeInterval to 15s if
+// unset.
+func New(cfg Config) *Exporter {
+	if cfg.MinScrapeInterval <= 0 {
+		cfg.MinScrapeInterval = 15 * time.Second
+	}
+
+	e := &Exporter{
+		cfg:         cfg,
+		poolStates:  make(map[PoolTarget]*targetState),
+		tokenStates: make(map[TokenTarget]*targetState),
+	}
+
+	for _, p := range cfg.Pools {
+		e.poolStates[p] = &targetState{}
+	}
+	for _, tk := range cfg.Tokens {
+		e.tokenStates[tk] = &targetState{}
+	}
+
+	return e
+}
+
+// Describe implements prometheus.Collector.
+func (e *Exporter) Describe(ch chan<- *prometheus.Desc) {
+	ch <- poolVolumeUSD
+	ch <- poolPriceUSD
+	ch <- poolPriceChange24h
+	ch <- tokenLiquidityUSD
+	ch <- systemChains
+	ch <- systemPools
+	ch <- systemTokens
+	ch <- systemFactories
+}
+
+// Collect implements prometheus.Collector. Each scrape-eligible target is
+// refreshed in parallel so one slow network doesn't stall the others.
+func (e *Exporter) Collect(ch chan<- prometheus.Metric) {
+	var wg sync.WaitGroup
+
+	for target, state := range e.poolStates {
+		if !state.stale(e.cfg.MinScrapeInterval) {
+			e.emitPool(ch, target, state)
+			continue
+		}
+
+		wg.Add(1)
+		go func(target PoolTarget, state *targetState) {
+			defer wg.Done()
+			e.refreshPool(target, state)
+			e.emitPool(ch, target, state)
+		}(target, state)
+	}
+
+	for target, state := range e.tokenStates {
+		if !state.stale(e.cfg.MinScrapeInterval) {
+			e.emitToken(ch, target, state)
+			continue
+		}
+
+		wg.Add(1)
+		go func(target TokenTarget, state *targetState) {
+			defer wg.Done()
+			e.refreshToken(target, state)
+			e.emitToken(ch, target, state)
+		}(target, state)
+	}
+
+	wg.Wait()
+
+	e.collectSystemStats(ch)
+}
+
+func (e *Exporter) refreshPool(target PoolTarget, state *targetState) {
+	pool, err := paprikahelpers.GetPoolDetails(target.Network, target.Address, false)
+	if err != nil {
+		return
+	}
+
+	state.mu.Lock()
+	state.pool = pool
+	state.lastUpdate = time.Now()
+	state.mu.Unlock()
+}
+
+func (e *Exporter) emitPool(ch chan<- prometheus.Metric, target PoolTarget, state *targetState) {
+	state.mu.Lock()
+	pool := state.pool
+	state.mu.Unlock()
+
+	if pool == nil {
+		return
+	}
+
+	symbol := ""
+	if len(pool.Tokens) > 0 {
+		symbol = pool.Tokens[0].Symbol
+	}
+
+	ch <- prometheus.MustNewConstMetric(poolVolumeUSD, prometheus.GaugeValue, pool.VolumeUSD,
+		target.Network, pool.DexName, target.Address, symbol)
+	ch <- prometheus.MustNewConstMetric(poolPriceUSD, prometheus.GaugeValue, pool.PriceUSD,
+		target.Network, pool.DexName, target.Address, symbol)
+	ch <- prometheus.MustNewConstMetric(poolPriceChange24h, prometheus.GaugeValue, pool.LastPriceChangeUSD24h,
+		target.Network, pool.DexName, target.Address, symbol)
+}
+
+func (e *Exporter) refreshToken(target TokenTarget, state *targetState) {
+	token, err := paprikahelpers.GetTokenDetails(target.Network, target.Address)
+	if err != nil {
+		return
+	}
+
+	state.mu.Lock()
+	state.token = token
+	state.lastUpdate = time.Now()
+	state.mu.Unlock()
+}
+
+func (e *Exporter) emitToken(ch chan<- prometheus.Metric, target TokenTarget, state *targetState) {
+	state.mu.Lock()
+	token := state.token
+	state.mu.Unlock()
+
+	if token == nil || token.Summary == nil {
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(tokenLiquidityUSD, prometheus.GaugeValue, token.Summary.LiquidityUSD,
+		target.Network, target.Address, token.Symbol)
+}
+
+func (e *Exporter) collectSystemStats(ch chan<- prometheus.Metric) {
+	stats, err := paprikahelpers.GetSystemStats()
+	if err != nil {
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(systemChains, prometheus.GaugeValue, float64(stats.Chains))
+	ch <- prometheus.MustNewConstMetric(systemPools, prometheus.GaugeValue, float64(stats.Pools))
+	ch <- prometheus.MustNewConstMetric(systemTokens, prometheus.GaugeValue, float64(stats.Tokens))
+	ch <- prometheus.MustNewConstMetric(systemFactories, prometheus.GaugeValue, float64(stats.Factories))
+}